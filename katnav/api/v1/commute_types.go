@@ -0,0 +1,260 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConditionDelayExceeded is true when the last journey took longer than the
+// maximum duration of a Commute
+const ConditionDelayExceeded = "DelayExceeded"
+
+// JourneySpec is a journey that is found on a schedule, it is described in the
+// same way as Directions without the refresh interval, elevation, places along
+// the route and snapshots
+type JourneySpec struct {
+	// Source is where the beginning of our journey is, either this or
+	// sourceRef needs to be set
+	// +optional
+	Source string `json:"source,omitempty"`
+	// Destination is the end of our journey, either this or destinationRef
+	// needs to be set
+	// +optional
+	Destination string `json:"destination,omitempty"`
+
+	// SourceRef is a Location in the same namespace to use as the source
+	// +optional
+	SourceRef *corev1.LocalObjectReference `json:"sourceRef,omitempty"`
+	// DestinationRef is a Location in the same namespace to use as the
+	// destination
+	// +optional
+	DestinationRef *corev1.LocalObjectReference `json:"destinationRef,omitempty"`
+
+	// Mode is how we will be travelling, defaults to driving
+	// +kubebuilder:default=driving
+	// +optional
+	Mode TravelMode `json:"mode,omitempty"`
+
+	// Avoid is a list of features that the route should stay away from
+	// +optional
+	Avoid []Avoid `json:"avoid,omitempty"`
+
+	// Alternatives will ask for more than one route when they are available
+	// +optional
+	Alternatives bool `json:"alternatives,omitempty"`
+
+	// Waypoints is an ordered list of stops between the source and destination,
+	// each one can be an address, a "lat,lng" pair or a "place_id:" prefixed ID
+	// +optional
+	Waypoints []string `json:"waypoints,omitempty"`
+
+	// OptimizeWaypoints allows the waypoints to be re-ordered into a more
+	// efficient journey
+	// +optional
+	OptimizeWaypoints bool `json:"optimizeWaypoints,omitempty"`
+
+	// Provider is the routing backend to use, when it isn't set the default
+	// provider of the controller is used
+	// +kubebuilder:validation:Enum=google;osrm
+	// +optional
+	Provider string `json:"provider,omitempty"`
+
+	// SecretRef is a Secret in the same namespace that holds the API key for
+	// the provider, when it isn't set the key configured on the controller is used
+	// +optional
+	SecretRef *corev1.SecretKeySelector `json:"secretRef,omitempty"`
+
+	// DepartureTime is either "now" or an RFC 3339 time to leave at, setting
+	// it when driving will return the duration in traffic
+	// +optional
+	DepartureTime string `json:"departureTime,omitempty"`
+
+	// ArrivalTime is when we want to arrive, this is only used by transit
+	// +optional
+	ArrivalTime *metav1.Time `json:"arrivalTime,omitempty"`
+
+	// TrafficModel is the assumption used when calculating the duration in
+	// traffic, it needs a departure time
+	// +optional
+	TrafficModel TrafficModel `json:"trafficModel,omitempty"`
+
+	// Notifications are sent when the route changes or takes too long
+	// +optional
+	Notifications *Notifications `json:"notifications,omitempty"`
+
+	// Language is the BCP 47 language of the instructions and durations,
+	// e.g. "de" or "en-GB"
+	// +optional
+	Language string `json:"language,omitempty"`
+
+	// Region is the ccTLD region code that addresses are biased towards,
+	// e.g. "uk"
+	// +optional
+	Region string `json:"region,omitempty"`
+
+	// Units are used for the distances, they default to metric
+	// +optional
+	Units Units `json:"units,omitempty"`
+
+	// Exports are the formats that the routes are written to, they are kept
+	// in a ConfigMap named after the object with an "-export" suffix
+	// +optional
+	Exports []ExportFormat `json:"exports,omitempty"`
+}
+
+// DirectionsSpec returns the journey as the spec of a Directions
+func (j *JourneySpec) DirectionsSpec() DirectionsSpec {
+	return DirectionsSpec{
+		Source:            j.Source,
+		Destination:       j.Destination,
+		SourceRef:         j.SourceRef,
+		DestinationRef:    j.DestinationRef,
+		Mode:              j.Mode,
+		Avoid:             j.Avoid,
+		Alternatives:      j.Alternatives,
+		Waypoints:         j.Waypoints,
+		OptimizeWaypoints: j.OptimizeWaypoints,
+		Provider:          j.Provider,
+		SecretRef:         j.SecretRef,
+		DepartureTime:     j.DepartureTime,
+		ArrivalTime:       j.ArrivalTime,
+		TrafficModel:      j.TrafficModel,
+		Notifications:     j.Notifications,
+		Language:          j.Language,
+		Region:            j.Region,
+		Units:             j.Units,
+		Exports:           j.Exports,
+	}
+}
+
+// CommuteSpec defines the desired state of Commute
+type CommuteSpec struct {
+	// The journey is found each time that the schedule is due
+	JourneySpec `json:",inline"`
+
+	// Schedule is a cron expression of when the commute starts, e.g.
+	// "0 8 * * 1-5" for 08:00 on weekdays
+	Schedule string `json:"schedule"`
+
+	// TimeZone is the IANA time zone of the schedule, e.g. "Europe/London",
+	// it defaults to UTC
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// MaxDuration is the longest that the commute should take, if it is
+	// expected to take longer the commute is delayed
+	MaxDuration metav1.Duration `json:"maxDuration"`
+
+	// Suspend stops any further journeys from being queried
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+// CommuteStatus defines the observed state of Commute
+type CommuteStatus struct {
+	// RouteSummary is the summary of the route that was found
+	// +optional
+	RouteSummary string `json:"routeSummary,omitempty"`
+
+	// Distance is the human readable length of the commute
+	// +optional
+	Distance string `json:"distance,omitempty"`
+
+	// Duration is how long the commute is expected to take, in traffic when
+	// the provider returns it
+	// +optional
+	Duration string `json:"duration,omitempty"`
+
+	// DurationSeconds is how long the commute is expected to take in seconds
+	// +optional
+	DurationSeconds int64 `json:"durationSeconds,omitempty"`
+
+	// Delay is how much longer than the maximum duration the commute will take
+	// +optional
+	Delay string `json:"delay,omitempty"`
+
+	// Error is why the last journey couldn't be found
+	// +optional
+	Error string `json:"error,omitempty"`
+
+	// Departure is when the last scheduled journey left
+	// +optional
+	Departure *JourneyTime `json:"departure,omitempty"`
+
+	// Arrival is when the last scheduled journey is expected to arrive
+	// +optional
+	Arrival *JourneyTime `json:"arrival,omitempty"`
+
+	// LastScheduleTime is when the journey was last queried
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+	// NextScheduleTime is when the journey will next be queried
+	// +optional
+	NextScheduleTime *metav1.Time `json:"nextScheduleTime,omitempty"`
+
+	// ExportConfigMap is the name of the ConfigMap holding the exports
+	// +optional
+	ExportConfigMap string `json:"exportConfigMap,omitempty"`
+
+	// TravelTimes is a history of the duration of each scheduled journey, the
+	// oldest are removed first
+	// +optional
+	TravelTimes []TravelTime `json:"travelTimes,omitempty"`
+
+	// ObservedGeneration is the generation of the spec that the status describes
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions are the latest observations of the state of the Commute
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Schedule",type=string,JSONPath=`.spec.schedule`
+//+kubebuilder:printcolumn:name="Max",type=string,JSONPath=`.spec.maxDuration`
+//+kubebuilder:printcolumn:name="Duration",type=string,JSONPath=`.status.duration`
+//+kubebuilder:printcolumn:name="Delayed",type=string,JSONPath=`.status.conditions[?(@.type=="DelayExceeded")].status`
+//+kubebuilder:printcolumn:name="Last",type=date,JSONPath=`.status.lastScheduleTime`
+
+// Commute is the Schema for the commutes API
+type Commute struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CommuteSpec   `json:"spec,omitempty"`
+	Status CommuteStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// CommuteList contains a list of Commute
+type CommuteList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Commute `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Commute{}, &CommuteList{})
+}
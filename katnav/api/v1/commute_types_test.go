@@ -0,0 +1,58 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestJourneyDirectionsSpec(t *testing.T) {
+	journey := JourneySpec{
+		Source:            "Kings Cross, London",
+		DestinationRef:    &corev1.LocalObjectReference{Name: "office"},
+		Mode:              TravelModeTransit,
+		Avoid:             []Avoid{AvoidTolls},
+		Alternatives:      true,
+		Waypoints:         []string{"Tower Bridge"},
+		OptimizeWaypoints: true,
+		Provider:          "google",
+		DepartureTime:     DepartureTimeNow,
+		Language:          "en-GB",
+		Region:            "uk",
+		Units:             UnitsImperial,
+		Exports:           []ExportFormat{ExportFormatGeoJSON},
+	}
+	spec := journey.DirectionsSpec()
+
+	// Every field of the journey is copied to the field with the same name
+	j := reflect.ValueOf(journey)
+	d := reflect.ValueOf(spec)
+	for x := 0; x < j.NumField(); x++ {
+		name := j.Type().Field(x).Name
+		field := d.FieldByName(name)
+		if !field.IsValid() {
+			t.Errorf("Directions has no field %s", name)
+			continue
+		}
+		if !reflect.DeepEqual(j.Field(x).Interface(), field.Interface()) {
+			t.Errorf("%s wasn't copied", name)
+		}
+	}
+}
@@ -17,29 +17,557 @@ limitations under the License.
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
+// TravelMode is the mode of transport used to calculate a route
+// +kubebuilder:validation:Enum=driving;walking;bicycling;transit
+type TravelMode string
+
+const (
+	TravelModeDriving   TravelMode = "driving"
+	TravelModeWalking   TravelMode = "walking"
+	TravelModeBicycling TravelMode = "bicycling"
+	TravelModeTransit   TravelMode = "transit"
+)
+
+// Avoid is a feature that a calculated route should avoid
+// +kubebuilder:validation:Enum=tolls;highways;ferries;indoor
+type Avoid string
+
+const (
+	AvoidTolls    Avoid = "tolls"
+	AvoidHighways Avoid = "highways"
+	AvoidFerries  Avoid = "ferries"
+	AvoidIndoor   Avoid = "indoor"
+)
+
+// TrafficModel is the assumption used when calculating the time in traffic
+// +kubebuilder:validation:Enum=best_guess;pessimistic;optimistic
+type TrafficModel string
+
+const (
+	TrafficModelBestGuess   TrafficModel = "best_guess"
+	TrafficModelPessimistic TrafficModel = "pessimistic"
+	TrafficModelOptimistic  TrafficModel = "optimistic"
+)
+
+// Units is the system of units used for distances
+// +kubebuilder:validation:Enum=metric;imperial
+type Units string
+
+const (
+	UnitsMetric   Units = "metric"
+	UnitsImperial Units = "imperial"
+)
+
+// ExportFormat is a file format that routes can be exported to
+// +kubebuilder:validation:Enum=geojson;gpx;kml
+type ExportFormat string
+
+const (
+	ExportFormatGeoJSON ExportFormat = "geojson"
+	ExportFormatGPX     ExportFormat = "gpx"
+	ExportFormatKML     ExportFormat = "kml"
+)
+
+// ElevationSpec configures the elevation profile of a route
+type ElevationSpec struct {
+	// ResolutionMeters is the distance between samples along the route, it is
+	// increased for long routes so that no more than 1024 samples are taken
+	// +kubebuilder:default=100
+	// +kubebuilder:validation:Minimum=10
+	// +optional
+	ResolutionMeters int32 `json:"resolutionMeters,omitempty"`
+}
+
+// AlongRouteSearch looks for places close to the path of a route
+type AlongRouteSearch struct {
+	// Name identifies the search in the status, e.g. "fuel"
+	Name string `json:"name"`
+
+	// Type is a place type to search for, e.g. "gas_station"
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// Keyword is matched against everything that is known about a place,
+	// e.g. "EV charger"
+	// +optional
+	Keyword string `json:"keyword,omitempty"`
+
+	// RadiusMeters is the furthest that a place can be from the route
+	// +kubebuilder:default=500
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=50000
+	// +optional
+	RadiusMeters int32 `json:"radiusMeters,omitempty"`
+
+	// MaxResults is the most places that are listed, the places with the
+	// shortest detour are kept
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	MaxResults int32 `json:"maxResults,omitempty"`
+}
+
+// DepartureTimeNow departs at the time the route is calculated
+const DepartureTimeNow = "now"
+
 // DirectionsSpec defines the desired state of Directions
 type DirectionsSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
-	// Source is where the beginning of our journey is
-	Source string `json:"source"`
-	// Destination is the end of our journey
-	Destination string `json:"destination"`
+	// Source is where the beginning of our journey is, either this or
+	// sourceRef needs to be set
+	// +optional
+	Source string `json:"source,omitempty"`
+	// Destination is the end of our journey, either this or destinationRef
+	// needs to be set
+	// +optional
+	Destination string `json:"destination,omitempty"`
+
+	// SourceRef is a Location in the same namespace to use as the source
+	// +optional
+	SourceRef *corev1.LocalObjectReference `json:"sourceRef,omitempty"`
+	// DestinationRef is a Location in the same namespace to use as the
+	// destination
+	// +optional
+	DestinationRef *corev1.LocalObjectReference `json:"destinationRef,omitempty"`
+
+	// Mode is how we will be travelling, defaults to driving
+	// +kubebuilder:default=driving
+	// +optional
+	Mode TravelMode `json:"mode,omitempty"`
+
+	// Avoid is a list of features that the route should stay away from
+	// +optional
+	Avoid []Avoid `json:"avoid,omitempty"`
+
+	// Alternatives will ask for more than one route when they are available
+	// +optional
+	Alternatives bool `json:"alternatives,omitempty"`
+
+	// Waypoints is an ordered list of stops between the source and destination,
+	// each one can be an address, a "lat,lng" pair or a "place_id:" prefixed ID
+	// +optional
+	Waypoints []string `json:"waypoints,omitempty"`
+
+	// OptimizeWaypoints allows the waypoints to be re-ordered into a more
+	// efficient journey
+	// +optional
+	OptimizeWaypoints bool `json:"optimizeWaypoints,omitempty"`
+
+	// Provider is the routing backend to use, when it isn't set the default
+	// provider of the controller is used
+	// +kubebuilder:validation:Enum=google;osrm
+	// +optional
+	Provider string `json:"provider,omitempty"`
+
+	// SecretRef is a Secret in the same namespace that holds the API key for
+	// the provider, when it isn't set the key configured on the controller is used
+	// +optional
+	SecretRef *corev1.SecretKeySelector `json:"secretRef,omitempty"`
+
+	// RefreshInterval is how often the route is queried again when the spec
+	// hasn't changed, when it isn't set the route is only queried on changes.
+	// It can't be shorter than a minute.
+	// +optional
+	RefreshInterval *metav1.Duration `json:"refreshInterval,omitempty"`
+
+	// DepartureTime is either "now" or an RFC 3339 time to leave at, setting
+	// it when driving will return the duration in traffic
+	// +optional
+	DepartureTime string `json:"departureTime,omitempty"`
+
+	// ArrivalTime is when we want to arrive, this is only used by transit
+	// +optional
+	ArrivalTime *metav1.Time `json:"arrivalTime,omitempty"`
+
+	// TrafficModel is the assumption used when calculating the duration in
+	// traffic, it needs a departure time
+	// +optional
+	TrafficModel TrafficModel `json:"trafficModel,omitempty"`
+
+	// Notifications are sent when the route changes or takes too long
+	// +optional
+	Notifications *Notifications `json:"notifications,omitempty"`
+
+	// Language is the BCP 47 language of the instructions and durations,
+	// e.g. "de" or "en-GB"
+	// +optional
+	Language string `json:"language,omitempty"`
+
+	// Region is the ccTLD region code that addresses are biased towards,
+	// e.g. "uk"
+	// +optional
+	Region string `json:"region,omitempty"`
+
+	// Units are used for the distances, they default to metric
+	// +optional
+	Units Units `json:"units,omitempty"`
+
+	// Exports are the formats that the routes are written to, they are kept
+	// in a ConfigMap named after the Directions with an "-export" suffix
+	// +optional
+	Exports []ExportFormat `json:"exports,omitempty"`
+
+	// Elevation samples the elevation along the first route, the profile is
+	// kept in a ConfigMap named after the Directions with an "-elevation" suffix
+	// +optional
+	Elevation *ElevationSpec `json:"elevation,omitempty"`
+
+	// AlongRoute are searches for places close to the first route, e.g. fuel
+	// stations or EV chargers
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	AlongRoute []AlongRouteSearch `json:"alongRoute,omitempty"`
+
+	// SnapshotHistoryLimit is the number of RouteSnapshots that are kept, one
+	// is recorded each time the route is found and the oldest are removed
+	// first. Zero stops snapshots from being recorded
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	SnapshotHistoryLimit *int32 `json:"snapshotHistoryLimit,omitempty"`
+}
+
+// RoutePlace is a place close to a route
+type RoutePlace struct {
+	// Name is the name of the place
+	Name string `json:"name"`
+
+	// PlaceID is the ID of the place with the provider
+	// +optional
+	PlaceID string `json:"placeID,omitempty"`
+
+	// Address is the address or vicinity of the place
+	// +optional
+	Address string `json:"address,omitempty"`
+
+	// Location is the coordinates of the place
+	Location LatLng `json:"location"`
+
+	// Rating is the average rating of the place, from 1.0 to 5.0
+	// +optional
+	Rating float64 `json:"rating,omitempty"`
+
+	// DistanceAlongRouteMeters is how far along the route the place is
+	DistanceAlongRouteMeters int `json:"distanceAlongRouteMeters"`
+
+	// DistanceFromRouteMeters is how far the place is from the route
+	DistanceFromRouteMeters int `json:"distanceFromRouteMeters"`
+
+	// DetourMeters is an estimate of the extra distance travelled to visit the
+	// place, which is there and back from the closest point on the route
+	DetourMeters int `json:"detourMeters"`
+}
+
+// AlongRouteResult are the places found by a search along the route
+type AlongRouteResult struct {
+	// Name is the name of the search
+	Name string `json:"name"`
+
+	// Places are in the order that they are passed along the route
+	// +optional
+	Places []RoutePlace `json:"places,omitempty"`
+}
+
+// ElevationSummary describes the climbing along a route
+type ElevationSummary struct {
+	// AscentMeters is the total height climbed
+	AscentMeters float64 `json:"ascentMeters"`
+
+	// DescentMeters is the total height descended
+	DescentMeters float64 `json:"descentMeters"`
+
+	// MaxMeters is the highest elevation along the route
+	MaxMeters float64 `json:"maxMeters"`
+
+	// MinMeters is the lowest elevation along the route
+	MinMeters float64 `json:"minMeters"`
+
+	// Samples is the number of points along the route that were sampled
+	Samples int `json:"samples"`
+
+	// ResolutionMeters is the distance that was used between samples
+	ResolutionMeters float64 `json:"resolutionMeters"`
+
+	// PathHash is a hash of the samples, the elevation is only queried again
+	// when it changes
+	// +optional
+	PathHash string `json:"pathHash,omitempty"`
+}
+
+// TravelTime is a duration that was observed at a point in time
+type TravelTime struct {
+	// Time is when the route was queried
+	Time metav1.Time `json:"time"`
+
+	// DurationSeconds is the duration of the route without traffic
+	DurationSeconds int64 `json:"durationSeconds"`
+
+	// DurationInTrafficSeconds is the duration of the route in traffic
+	// +optional
+	DurationInTrafficSeconds int64 `json:"durationInTrafficSeconds,omitempty"`
+}
+
+// JourneyTime is when a journey passes a location, in UTC and in the local
+// time zone of the location
+type JourneyTime struct {
+	// Time is the time in UTC
+	Time metav1.Time `json:"time"`
+
+	// Local is the time in the local time zone as RFC 3339, e.g.
+	// "2021-06-01T09:30:00+01:00", it is only set when the time zone is known
+	// +optional
+	Local string `json:"local,omitempty"`
+
+	// TimeZone is the IANA time zone of the location, e.g. "Europe/London"
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// Abbreviation is the name of the zone at the time, e.g. "BST"
+	// +optional
+	Abbreviation string `json:"abbreviation,omitempty"`
+}
+
+// LatLng is a pair of coordinates
+type LatLng struct {
+	// Lat is the latitude in degrees
+	Lat float64 `json:"lat"`
+
+	// Lng is the longitude in degrees
+	Lng float64 `json:"lng"`
+}
+
+// Step is a single instruction within a leg of a route
+type Step struct {
+	// Instruction is the plain text instruction for this step
+	Instruction string `json:"instruction"`
+
+	// Maneuver is the action to take (e.g. turn-left), this is only set when
+	// the provider returns one
+	// +optional
+	Maneuver string `json:"maneuver,omitempty"`
+
+	// TravelMode is how this step is travelled
+	// +optional
+	TravelMode string `json:"travelMode,omitempty"`
+
+	// DistanceMeters is the distance covered by this step
+	DistanceMeters int `json:"distanceMeters"`
+
+	// DurationSeconds is the time this step will take
+	DurationSeconds int64 `json:"durationSeconds"`
+
+	// StartLocation is the coordinates where this step begins
+	StartLocation LatLng `json:"startLocation"`
+
+	// EndLocation is the coordinates where this step ends
+	EndLocation LatLng `json:"endLocation"`
+
+	// Polyline is the encoded polyline of the path for this step
+	// +optional
+	Polyline string `json:"polyline,omitempty"`
+
+	// Transit describes the public transport taken for this step, it is only
+	// set for transit steps
+	// +optional
+	Transit *TransitDetails `json:"transit,omitempty"`
+}
+
+// TransitStop is a stop or station on a transit line
+type TransitStop struct {
+	// Name is the name of the stop
+	Name string `json:"name"`
+
+	// Location is the coordinates of the stop
+	// +optional
+	Location LatLng `json:"location,omitempty"`
+}
+
+// TransitDetails describes the public transport taken for a step
+type TransitDetails struct {
+	// LineName is the full name of the line, e.g. "Jubilee"
+	// +optional
+	LineName string `json:"lineName,omitempty"`
+
+	// LineShortName is the short name of the line, e.g. the bus number
+	// +optional
+	LineShortName string `json:"lineShortName,omitempty"`
+
+	// VehicleType is the type of vehicle on the line, e.g. BUS or SUBWAY
+	// +optional
+	VehicleType string `json:"vehicleType,omitempty"`
+
+	// Agencies are the operators of the line
+	// +optional
+	Agencies []string `json:"agencies,omitempty"`
+
+	// Headsign is the direction of travel shown on the vehicle
+	// +optional
+	Headsign string `json:"headsign,omitempty"`
+
+	// DepartureStop is where the line is boarded
+	DepartureStop TransitStop `json:"departureStop"`
+
+	// ArrivalStop is where the line is left
+	ArrivalStop TransitStop `json:"arrivalStop"`
+
+	// DepartureTime is the scheduled time of departure
+	// +optional
+	DepartureTime *metav1.Time `json:"departureTime,omitempty"`
+
+	// ArrivalTime is the scheduled time of arrival
+	// +optional
+	ArrivalTime *metav1.Time `json:"arrivalTime,omitempty"`
+
+	// NumStops is the number of stops, including the arrival stop
+	// +optional
+	NumStops int `json:"numStops,omitempty"`
+}
+
+// Fare is the total cost of the tickets for a route
+type Fare struct {
+	// Currency is the ISO 4217 code of the currency
+	Currency string `json:"currency"`
+
+	// Value is the total fare in the currency
+	Value float64 `json:"value"`
+
+	// Text is the formatted fare, e.g. "£2.80"
+	// +optional
+	Text string `json:"text,omitempty"`
+}
+
+// Leg is a part of a route between two stops
+type Leg struct {
+	// StartLocation is the address where this leg begins
+	StartLocation string `json:"startLocation"`
+
+	// EndLocation is the address where this leg ends
+	EndLocation string `json:"endLocation"`
+
+	// StartCoordinates is the coordinates where this leg begins
+	// +optional
+	StartCoordinates LatLng `json:"startCoordinates,omitempty"`
+
+	// EndCoordinates is the coordinates where this leg ends
+	// +optional
+	EndCoordinates LatLng `json:"endCoordinates,omitempty"`
+
+	// Distance is the distance of this leg
+	Distance string `json:"distance"`
+
+	// DistanceMeters is the distance of this leg in meters
+	DistanceMeters int `json:"distanceMeters"`
+
+	// Duration is the amount of time this leg will take
+	Duration string `json:"duration"`
+
+	// DurationSeconds is the amount of time this leg will take in seconds
+	DurationSeconds int64 `json:"durationSeconds"`
+
+	// DurationInTrafficSeconds is the amount of time this leg will take in
+	// traffic, this is only set when there is a departure time
+	// +optional
+	DurationInTrafficSeconds int64 `json:"durationInTrafficSeconds,omitempty"`
+
+	// Steps are the instructions to follow for this leg
+	// +optional
+	Steps []Step `json:"steps,omitempty"`
+}
+
+// Route is a single route that has been returned for our journey
+type Route struct {
+	// Summary gives a simple overview of the route
+	Summary string `json:"summary"`
+
+	// StartLocation is the start from the directions API
+	StartLocation string `json:"startLocation"`
+
+	// EndLocation is the end from the directions API
+	EndLocation string `json:"endLocation"`
+
+	// Distance is the total distance of the route
+	Distance string `json:"distance"`
+
+	// DistanceMeters is the total distance of the route in meters
+	DistanceMeters int `json:"distanceMeters"`
+
+	// Duration is the amount of time the route will take
+	Duration string `json:"duration"`
+
+	// DurationSeconds is the amount of time the route will take in seconds
+	DurationSeconds int64 `json:"durationSeconds"`
+
+	// DurationInTraffic is the amount of time the route will take in traffic
+	// +optional
+	DurationInTraffic string `json:"durationInTraffic,omitempty"`
+
+	// DurationInTrafficSeconds is the amount of time the route will take in
+	// traffic in seconds
+	// +optional
+	DurationInTrafficSeconds int64 `json:"durationInTrafficSeconds,omitempty"`
+
+	// Fare is the cost of a transit route, it is only set when the provider
+	// knows the fare for every transit step
+	// +optional
+	Fare *Fare `json:"fare,omitempty"`
+
+	// OverviewPolyline is the encoded polyline of the whole route
+	// +optional
+	OverviewPolyline string `json:"overviewPolyline,omitempty"`
+
+	// Warnings are any warnings that should be displayed alongside the route
+	// +optional
+	Warnings []string `json:"warnings,omitempty"`
+
+	// WaypointOrder is the order that the waypoints are visited in, this will
+	// only differ from the spec when the waypoints have been optimized
+	// +optional
+	WaypointOrder []int `json:"waypointOrder,omitempty"`
+
+	// Legs is the breakdown of the route between each of the waypoints
+	// +optional
+	Legs []Leg `json:"legs,omitempty"`
 }
 
+// Condition types that are reported on the status of Directions
+const (
+	// ConditionReady is true when the status holds an up to date route
+	ConditionReady = "Ready"
+	// ConditionRouteFound is true when the provider returned at least one route
+	ConditionRouteFound = "RouteFound"
+	// ConditionProviderError is true when the provider couldn't be queried
+	ConditionProviderError = "ProviderError"
+	// ConditionQuotaExhausted is true when the daily provider budget has been used up
+	ConditionQuotaExhausted = "QuotaExhausted"
+	// ConditionExported is true when the routes have been written to the exports
+	ConditionExported = "Exported"
+	// ConditionElevationFound is true when the elevation profile is up to date
+	ConditionElevationFound = "ElevationFound"
+	// ConditionPlacesFound is true when the places along the route are up to date
+	ConditionPlacesFound = "PlacesFound"
+	// ConditionTimeZonesResolved is true when the local departure and arrival
+	// times are known
+	ConditionTimeZonesResolved = "TimeZonesResolved"
+)
+
 // DirectionsStatus defines the observed state of Directions
 type DirectionsStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
-	// Directions is a list of directions to our destination
+	// Directions is a list of directions to our destination, it is built from
+	// the steps of the first route so that it can easily be read
 	Directions string `json:"directions"`
 
 	// Routesummary gives a simple overview of the route
@@ -54,15 +582,107 @@ type DirectionsStatus struct {
 	// Distance is the total distance of the journey
 	Distance string `json:"distance"`
 
+	// DistanceMeters is the total distance of the journey in meters
+	// +optional
+	DistanceMeters int `json:"distanceMeters,omitempty"`
+
 	// Duration is the amount of time the journey will take
 	Duration string `json:"duration"`
 
+	// DurationSeconds is the amount of time the journey will take in seconds
+	// +optional
+	DurationSeconds int64 `json:"durationSeconds,omitempty"`
+
+	// DurationInTraffic is the amount of time the journey will take in traffic
+	// +optional
+	DurationInTraffic string `json:"durationInTraffic,omitempty"`
+
+	// DurationInTrafficSeconds is the amount of time the journey will take in
+	// traffic in seconds
+	// +optional
+	DurationInTrafficSeconds int64 `json:"durationInTrafficSeconds,omitempty"`
+
+	// Fare is the cost of the journey when it uses transit
+	// +optional
+	Fare *Fare `json:"fare,omitempty"`
+
+	// Departure is when the journey leaves, which is the departure time of
+	// the spec or when the route was found
+	// +optional
+	Departure *JourneyTime `json:"departure,omitempty"`
+
+	// Arrival is when the journey is expected to arrive
+	// +optional
+	Arrival *JourneyTime `json:"arrival,omitempty"`
+
+	// TravelTimes is a rolling history of the duration of the journey each
+	// time that it has been queried, the oldest are removed first
+	// +optional
+	TravelTimes []TravelTime `json:"travelTimes,omitempty"`
+
+	// Routes is every route that was returned, the first one is the route
+	// that is described by the fields above
+	// +optional
+	Routes []Route `json:"routes,omitempty"`
+
 	// Error captures an error message if the route isn't possible
 	Error string `json:"error,omitempty"`
+
+	// SpecHash is a hash of the spec that was used for the last query
+	// +optional
+	SpecHash string `json:"specHash,omitempty"`
+
+	// LastQueryTime is when the provider was last queried for a route
+	// +optional
+	LastQueryTime *metav1.Time `json:"lastQueryTime,omitempty"`
+
+	// FromCache is true when the routes came from the route cache rather than
+	// a new query to the provider
+	// +optional
+	FromCache bool `json:"fromCache,omitempty"`
+
+	// ExportConfigMap is the name of the ConfigMap holding the exports
+	// +optional
+	ExportConfigMap string `json:"exportConfigMap,omitempty"`
+
+	// Elevation summarises the elevation profile of the first route
+	// +optional
+	Elevation *ElevationSummary `json:"elevation,omitempty"`
+
+	// ElevationConfigMap is the name of the ConfigMap holding the profile
+	// +optional
+	ElevationConfigMap string `json:"elevationConfigMap,omitempty"`
+
+	// AlongRoute are the places that were found by each search
+	// +optional
+	AlongRoute []AlongRouteResult `json:"alongRoute,omitempty"`
+
+	// AlongRouteHash is a hash of the path and the searches, the places are
+	// only searched for again when it changes
+	// +optional
+	AlongRouteHash string `json:"alongRouteHash,omitempty"`
+
+	// ObservedGeneration is the generation of the spec that the status describes
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions are the latest observations of the state of the Directions
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="Mode",type=string,JSONPath=`.spec.mode`
+//+kubebuilder:printcolumn:name="Summary",type=string,JSONPath=`.status.routeSummary`
+//+kubebuilder:printcolumn:name="Distance",type=string,JSONPath=`.status.distance`
+//+kubebuilder:printcolumn:name="Duration",type=string,JSONPath=`.status.duration`
+//+kubebuilder:printcolumn:name="In Traffic",type=string,JSONPath=`.status.durationInTraffic`,priority=1
+//+kubebuilder:printcolumn:name="Arrival",type=string,JSONPath=`.status.arrival.local`,priority=1
+//+kubebuilder:printcolumn:name="Ascent",type=number,JSONPath=`.status.elevation.ascentMeters`,priority=1
 
 // Directions is the Schema for the directions API
 type Directions struct {
@@ -0,0 +1,193 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// log is for logging in this package.
+var directionslog = logf.Log.WithName("directions-resource")
+
+// SetupWebhookWithManager registers the defaulting and validating webhooks
+func (r *Directions) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-katnav-fnnrn-me-v1-directions,mutating=true,failurePolicy=fail,sideEffects=None,groups=katnav.fnnrn.me,resources=directions,verbs=create;update,versions=v1,name=mdirections.kb.io,admissionReviewVersions={v1,v1beta1}
+
+var _ webhook.Defaulter = &Directions{}
+
+// MinRefreshInterval is the shortest refresh interval, every refresh is a paid
+// query so a shorter one would use up the daily budget
+const MinRefreshInterval = time.Minute
+
+// imperialRegions are the regions whose roads are signed in miles
+var imperialRegions = map[string]bool{
+	"us": true,
+	"uk": true,
+	"gb": true,
+	"lr": true,
+	"mm": true,
+}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (r *Directions) Default() {
+	directionslog.Info("default", "name", r.Name)
+	r.Spec.Default()
+}
+
+// Default sets the mode to driving and picks the units from the region, which
+// is how Google would have chosen them
+func (s *DirectionsSpec) Default() {
+	if s.Mode == "" {
+		s.Mode = TravelModeDriving
+	}
+	if s.Units == "" {
+		if imperialRegions[strings.ToLower(s.Region)] {
+			s.Units = UnitsImperial
+		} else {
+			s.Units = UnitsMetric
+		}
+	}
+}
+
+//+kubebuilder:webhook:path=/validate-katnav-fnnrn-me-v1-directions,mutating=false,failurePolicy=fail,sideEffects=None,groups=katnav.fnnrn.me,resources=directions,verbs=create;update,versions=v1,name=vdirections.kb.io,admissionReviewVersions={v1,v1beta1}
+
+var _ webhook.Validator = &Directions{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *Directions) ValidateCreate() error {
+	directionslog.Info("validate create", "name", r.Name)
+	return r.validate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *Directions) ValidateUpdate(old runtime.Object) error {
+	directionslog.Info("validate update", "name", r.Name)
+	return r.validate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *Directions) ValidateDelete() error {
+	return nil
+}
+
+func (r *Directions) validate() error {
+	errs := r.Spec.Validate(field.NewPath("spec"))
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(schema.GroupKind{Group: GroupVersion.Group, Kind: "Directions"}, r.Name, errs)
+}
+
+// Validate checks the journey for mistakes that the provider would otherwise
+// reject when the route is calculated
+func (s *DirectionsSpec) Validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if strings.TrimSpace(s.Source) == "" && s.SourceRef == nil {
+		errs = append(errs, field.Required(path.Child("source"), "either source or sourceRef needs to be set"))
+	}
+	if strings.TrimSpace(s.Destination) == "" && s.DestinationRef == nil {
+		errs = append(errs, field.Required(path.Child("destination"), "either destination or destinationRef needs to be set"))
+	}
+	errs = append(errs, validateLocation(path.Child("source"), s.Source, s.Provider)...)
+	errs = append(errs, validateLocation(path.Child("destination"), s.Destination, s.Provider)...)
+	if s.SourceRef != nil && s.DestinationRef != nil {
+		if s.SourceRef.Name == s.DestinationRef.Name {
+			errs = append(errs, field.Invalid(path.Child("destinationRef", "name"), s.DestinationRef.Name, "must be a different location to the source"))
+		}
+	} else if s.SourceRef == nil && s.DestinationRef == nil && strings.TrimSpace(s.Source) != "" && sameLocation(s.Source, s.Destination) {
+		errs = append(errs, field.Invalid(path.Child("destination"), s.Destination, "must be a different location to the source"))
+	}
+	for x := range s.Waypoints {
+		if strings.TrimSpace(s.Waypoints[x]) == "" {
+			errs = append(errs, field.Required(path.Child("waypoints").Index(x), "can't be empty"))
+			continue
+		}
+		errs = append(errs, validateLocation(path.Child("waypoints").Index(x), s.Waypoints[x], s.Provider)...)
+	}
+
+	switch s.Mode {
+	case "", TravelModeDriving, TravelModeWalking, TravelModeBicycling, TravelModeTransit:
+	default:
+		errs = append(errs, field.NotSupported(path.Child("mode"), s.Mode,
+			[]string{string(TravelModeDriving), string(TravelModeWalking), string(TravelModeBicycling), string(TravelModeTransit)}))
+	}
+	switch s.Units {
+	case "", UnitsMetric, UnitsImperial:
+	default:
+		errs = append(errs, field.NotSupported(path.Child("units"), s.Units, []string{string(UnitsMetric), string(UnitsImperial)}))
+	}
+
+	if s.DepartureTime != "" && s.DepartureTime != DepartureTimeNow {
+		if _, err := time.Parse(time.RFC3339, s.DepartureTime); err != nil {
+			errs = append(errs, field.Invalid(path.Child("departureTime"), s.DepartureTime, `must be "now" or an RFC 3339 time`))
+		}
+	}
+	if s.ArrivalTime != nil {
+		if s.DepartureTime != "" {
+			errs = append(errs, field.Forbidden(path.Child("arrivalTime"), "can't be set with a departureTime"))
+		}
+		if s.Mode != TravelModeTransit {
+			errs = append(errs, field.Forbidden(path.Child("arrivalTime"), "is only used by transit"))
+		}
+	}
+	if s.RefreshInterval != nil && s.RefreshInterval.Duration < MinRefreshInterval {
+		errs = append(errs, field.Invalid(path.Child("refreshInterval"), s.RefreshInterval.Duration.String(), "must be at least "+MinRefreshInterval.String()))
+	}
+	if s.TrafficModel != "" && s.DepartureTime == "" {
+		errs = append(errs, field.Required(path.Child("departureTime"), "is needed by the trafficModel"))
+	}
+	for x := range s.AlongRoute {
+		if s.AlongRoute[x].Type == "" && s.AlongRoute[x].Keyword == "" {
+			errs = append(errs, field.Required(path.Child("alongRoute").Index(x), "either a type or a keyword needs to be set"))
+		}
+	}
+	return errs
+}
+
+// validateLocation checks that a location is a valid "lat,lng" pair when it is
+// meant to be one, OSRM has no geocoder so it can only use coordinates
+func validateLocation(path *field.Path, location string, provider string) field.ErrorList {
+	if location == "" {
+		return nil
+	}
+	if provider == "osrm" || LooksLikeLatLng(location) {
+		if _, err := ParseLatLng(location); err != nil {
+			return field.ErrorList{field.Invalid(path, location, err.Error())}
+		}
+	}
+	return nil
+}
+
+// sameLocation compares two locations, ignoring case and surrounding spaces
+func sameLocation(a, b string) bool {
+	return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+}
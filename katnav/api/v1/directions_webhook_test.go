@@ -0,0 +1,139 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestDirectionsDefault(t *testing.T) {
+	spec := DirectionsSpec{Source: "a", Destination: "b"}
+	spec.Default()
+	if spec.Mode != TravelModeDriving || spec.Units != UnitsMetric {
+		t.Errorf("expected driving and metric, got %s and %s", spec.Mode, spec.Units)
+	}
+
+	spec = DirectionsSpec{Source: "a", Destination: "b", Mode: TravelModeWalking, Region: "UK"}
+	spec.Default()
+	if spec.Mode != TravelModeWalking || spec.Units != UnitsImperial {
+		t.Errorf("expected walking and imperial, got %s and %s", spec.Mode, spec.Units)
+	}
+}
+
+func TestDirectionsValidate(t *testing.T) {
+	arrival := metav1.NewTime(time.Date(2021, 6, 1, 9, 0, 0, 0, time.UTC))
+	for _, test := range []struct {
+		name string
+		spec DirectionsSpec
+		// fields are the paths of the expected errors
+		fields []string
+	}{
+		{
+			name: "valid",
+			spec: DirectionsSpec{Source: "Kings Cross, London", Destination: "51.4826,-0.0077", Waypoints: []string{"Tower Bridge"}},
+		},
+		{
+			name: "valid references",
+			spec: DirectionsSpec{SourceRef: &corev1.LocalObjectReference{Name: "home"}, DestinationRef: &corev1.LocalObjectReference{Name: "work"}},
+		},
+		{
+			name:   "missing",
+			spec:   DirectionsSpec{Source: " "},
+			fields: []string{"spec.source", "spec.destination"},
+		},
+		{
+			name:   "identical",
+			spec:   DirectionsSpec{Source: "Greenwich", Destination: " greenwich"},
+			fields: []string{"spec.destination"},
+		},
+		{
+			name:   "identical references",
+			spec:   DirectionsSpec{SourceRef: &corev1.LocalObjectReference{Name: "home"}, DestinationRef: &corev1.LocalObjectReference{Name: "home"}},
+			fields: []string{"spec.destinationRef.name"},
+		},
+		{
+			name:   "malformed coordinates",
+			spec:   DirectionsSpec{Source: "91.0,0", Destination: "51.5,-0.1", Waypoints: []string{"51.5,1.2.3", ""}},
+			fields: []string{"spec.source", "spec.waypoints[0]", "spec.waypoints[1]"},
+		},
+		{
+			name:   "not a number",
+			spec:   DirectionsSpec{Source: "NaN,NaN", Destination: "51.5,Inf", Waypoints: []string{"-Inf,0"}},
+			fields: []string{"spec.source", "spec.destination", "spec.waypoints[0]"},
+		},
+		{
+			name:   "osrm address",
+			spec:   DirectionsSpec{Source: "Greenwich", Destination: "51.5,-0.1", Provider: "osrm"},
+			fields: []string{"spec.source"},
+		},
+		{
+			name:   "unknown mode",
+			spec:   DirectionsSpec{Source: "a", Destination: "b", Mode: "flying", Units: "furlongs"},
+			fields: []string{"spec.mode", "spec.units"},
+		},
+		{
+			name:   "departure and arrival",
+			spec:   DirectionsSpec{Source: "a", Destination: "b", Mode: TravelModeTransit, DepartureTime: DepartureTimeNow, ArrivalTime: &arrival},
+			fields: []string{"spec.arrivalTime"},
+		},
+		{
+			name:   "arrival when driving",
+			spec:   DirectionsSpec{Source: "a", Destination: "b", ArrivalTime: &arrival},
+			fields: []string{"spec.arrivalTime"},
+		},
+		{
+			name:   "invalid departure",
+			spec:   DirectionsSpec{Source: "a", Destination: "b", DepartureTime: "tomorrow"},
+			fields: []string{"spec.departureTime"},
+		},
+		{
+			name:   "search without a type",
+			spec:   DirectionsSpec{Source: "a", Destination: "b", AlongRoute: []AlongRouteSearch{{Name: "fuel", Type: "gas_station"}, {Name: "chargers"}}},
+			fields: []string{"spec.alongRoute[1]"},
+		},
+		{
+			name:   "traffic model without departure",
+			spec:   DirectionsSpec{Source: "a", Destination: "b", TrafficModel: TrafficModelPessimistic},
+			fields: []string{"spec.departureTime"},
+		},
+		{
+			name: "refresh interval",
+			spec: DirectionsSpec{Source: "a", Destination: "b", RefreshInterval: &metav1.Duration{Duration: time.Minute}},
+		},
+		{
+			name:   "refresh interval too short",
+			spec:   DirectionsSpec{Source: "a", Destination: "b", RefreshInterval: &metav1.Duration{Duration: time.Second}},
+			fields: []string{"spec.refreshInterval"},
+		},
+	} {
+		errs := test.spec.Validate(field.NewPath("spec"))
+		if len(errs) != len(test.fields) {
+			t.Errorf("%s: expected %d errors, got %v", test.name, len(test.fields), errs)
+			continue
+		}
+		for x := range errs {
+			if errs[x].Field != test.fields[x] {
+				t.Errorf("%s: expected an error for %s, got %v", test.name, test.fields[x], errs[x])
+			}
+		}
+	}
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DistanceMatrixSpec defines the desired state of DistanceMatrix
+type DistanceMatrixSpec struct {
+	// Origins are where each journey starts
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=25
+	Origins []string `json:"origins"`
+
+	// Destinations are where each journey ends
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=25
+	Destinations []string `json:"destinations"`
+
+	// Modes are the ways of travelling, a matrix is built for each of them
+	// +kubebuilder:default={driving}
+	// +optional
+	Modes []TravelMode `json:"modes,omitempty"`
+
+	// Avoid are features that the journeys should avoid
+	// +optional
+	Avoid []Avoid `json:"avoid,omitempty"`
+
+	// Provider is the routing provider to use, only google is able to build
+	// a distance matrix
+	// +kubebuilder:validation:Enum=google
+	// +optional
+	Provider string `json:"provider,omitempty"`
+
+	// SecretRef is a key in a Secret in the same namespace that holds the
+	// Google API key, when it isn't set the controller default is used
+	// +optional
+	SecretRef *corev1.SecretKeySelector `json:"secretRef,omitempty"`
+
+	// DepartureTime is either "now" or an RFC 3339 time to leave at
+	// +optional
+	DepartureTime string `json:"departureTime,omitempty"`
+
+	// TrafficModel is the assumption used when calculating the duration in
+	// traffic, it needs a departure time
+	// +optional
+	TrafficModel TrafficModel `json:"trafficModel,omitempty"`
+
+	// Language is the BCP 47 language of the addresses and durations
+	// +optional
+	Language string `json:"language,omitempty"`
+
+	// Units are used for the distances, they default to metric
+	// +optional
+	Units Units `json:"units,omitempty"`
+
+	// RefreshInterval is how often the matrix is queried again, when it isn't
+	// set the matrix is only queried on changes
+	// +optional
+	RefreshInterval *metav1.Duration `json:"refreshInterval,omitempty"`
+}
+
+// MatrixElement is the journey from an origin to a single destination
+type MatrixElement struct {
+	// Destination is the address of the destination
+	Destination string `json:"destination"`
+
+	// ErrorCode is why there is no journey to this destination, it is empty
+	// when a journey was found
+	// +optional
+	ErrorCode string `json:"errorCode,omitempty"`
+
+	// Distance is the human readable length of the journey
+	// +optional
+	Distance string `json:"distance,omitempty"`
+
+	// DistanceMeters is the length of the journey in meters
+	// +optional
+	DistanceMeters int `json:"distanceMeters,omitempty"`
+
+	// Duration is the human readable time the journey will take
+	// +optional
+	Duration string `json:"duration,omitempty"`
+
+	// DurationSeconds is the time the journey will take in seconds
+	// +optional
+	DurationSeconds int64 `json:"durationSeconds,omitempty"`
+
+	// DurationInTrafficSeconds is the time the journey will take in traffic
+	// +optional
+	DurationInTrafficSeconds int64 `json:"durationInTrafficSeconds,omitempty"`
+}
+
+// MatrixRow is every journey from a single origin
+type MatrixRow struct {
+	// Origin is the address of the origin
+	Origin string `json:"origin"`
+
+	// Elements are in the same order as the destinations
+	Elements []MatrixElement `json:"elements"`
+}
+
+// Matrix is every journey for a mode of travel
+type Matrix struct {
+	// Mode is the way of travelling
+	Mode TravelMode `json:"mode"`
+
+	// Rows are in the same order as the origins
+	Rows []MatrixRow `json:"rows"`
+}
+
+// DistanceMatrixStatus defines the observed state of DistanceMatrix
+type DistanceMatrixStatus struct {
+	// Matrices are the journeys for each mode of travel
+	// +optional
+	Matrices []Matrix `json:"matrices,omitempty"`
+
+	// Error is why the matrix couldn't be found
+	// +optional
+	Error string `json:"error,omitempty"`
+
+	// SpecHash is a hash of the spec that was last queried
+	// +optional
+	SpecHash string `json:"specHash,omitempty"`
+
+	// LastQueryTime is when the provider was last queried
+	// +optional
+	LastQueryTime *metav1.Time `json:"lastQueryTime,omitempty"`
+
+	// ObservedGeneration is the generation of the spec that the status describes
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions are the latest observations of the state of the DistanceMatrix
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:path=distancematrices,singular=distancematrix
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="Last Query",type=date,JSONPath=`.status.lastQueryTime`
+
+// DistanceMatrix is the Schema for the distancematrices API
+type DistanceMatrix struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DistanceMatrixSpec   `json:"spec,omitempty"`
+	Status DistanceMatrixStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// DistanceMatrixList contains a list of DistanceMatrix
+type DistanceMatrixList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DistanceMatrix `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DistanceMatrix{}, &DistanceMatrixList{})
+}
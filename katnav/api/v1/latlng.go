@@ -0,0 +1,52 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// latLngPattern matches anything that is trying to be a "lat,lng" pair rather
+// than an address, including the NaN and Inf values that ParseFloat accepts
+var latLngPattern = regexp.MustCompile(`^\s*([-+.\d]+|[-+]?(?i:nan|inf|infinity))\s*,\s*([-+.\d]+|[-+]?(?i:nan|inf|infinity))\s*$`)
+
+// LooksLikeLatLng returns true if a location is meant to be a "lat,lng" pair,
+// it may still be out of range
+func LooksLikeLatLng(location string) bool {
+	return latLngPattern.MatchString(location)
+}
+
+// ParseLatLng parses a "lat,lng" pair and checks that it is a valid position
+func ParseLatLng(location string) (LatLng, error) {
+	l := strings.Split(location, ",")
+	if len(l) != 2 {
+		return LatLng{}, fmt.Errorf("location %q is not a lat,lng pair", location)
+	}
+	lat, err := strconv.ParseFloat(strings.TrimSpace(l[0]), 64)
+	if err != nil || math.IsNaN(lat) || math.IsInf(lat, 0) || lat < -90 || lat > 90 {
+		return LatLng{}, fmt.Errorf("location %q has an invalid latitude", location)
+	}
+	lng, err := strconv.ParseFloat(strings.TrimSpace(l[1]), 64)
+	if err != nil || math.IsNaN(lng) || math.IsInf(lng, 0) || lng < -180 || lng > 180 {
+		return LatLng{}, fmt.Errorf("location %q has an invalid longitude", location)
+	}
+	return LatLng{Lat: lat, Lng: lng}, nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LocationSpec defines the desired state of Location, either an address or
+// coordinates should be set
+type LocationSpec struct {
+	// Address is geocoded to find the coordinates of the location
+	// +optional
+	Address string `json:"address,omitempty"`
+
+	// Coordinates are reverse geocoded to find the address of the location
+	// +optional
+	Coordinates *LatLng `json:"coordinates,omitempty"`
+
+	// SecretRef is a key in a Secret in the same namespace that holds the
+	// Google API key, when it isn't set the controller default is used
+	// +optional
+	SecretRef *corev1.SecretKeySelector `json:"secretRef,omitempty"`
+}
+
+// LocationStatus defines the observed state of Location
+type LocationStatus struct {
+	// FormattedAddress is the full address of the location
+	// +optional
+	FormattedAddress string `json:"formattedAddress,omitempty"`
+
+	// Coordinates are where the location is
+	// +optional
+	Coordinates *LatLng `json:"coordinates,omitempty"`
+
+	// PlaceID uniquely identifies the place with the provider
+	// +optional
+	PlaceID string `json:"placeID,omitempty"`
+
+	// TimeZone is the IANA time zone of the location, e.g. "Europe/London"
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// Error is why the location couldn't be resolved
+	// +optional
+	Error string `json:"error,omitempty"`
+
+	// SpecHash is a hash of the spec that was last resolved
+	// +optional
+	SpecHash string `json:"specHash,omitempty"`
+
+	// LastResolveTime is when the location was last resolved
+	// +optional
+	LastResolveTime *metav1.Time `json:"lastResolveTime,omitempty"`
+
+	// ObservedGeneration is the generation of the spec that the status describes
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions are the latest observations of the state of the Location
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="Address",type=string,JSONPath=`.status.formattedAddress`
+//+kubebuilder:printcolumn:name="Time Zone",type=string,JSONPath=`.status.timeZone`
+
+// Location is the Schema for the locations API
+type Location struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LocationSpec   `json:"spec,omitempty"`
+	Status LocationStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// LocationList contains a list of Location
+type LocationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Location `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Location{}, &LocationList{})
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SinkType is the format of the notifications that are sent to a sink
+// +kubebuilder:validation:Enum=webhook;slack
+type SinkType string
+
+const (
+	// SinkTypeWebhook posts the notification as JSON
+	SinkTypeWebhook SinkType = "webhook"
+	// SinkTypeSlack posts the notification to a Slack incoming webhook
+	SinkTypeSlack SinkType = "slack"
+)
+
+// ConditionDelivered is true when the last notification was delivered
+const ConditionDelivered = "Delivered"
+
+// Notifications are sent when a route changes or takes too long
+type Notifications struct {
+	// Sinks are the NotificationSinks in the same namespace to notify
+	Sinks []corev1.LocalObjectReference `json:"sinks"`
+
+	// DurationThreshold notifies the sinks when the route will take longer,
+	// a Commute uses its maxDuration instead
+	// +optional
+	DurationThreshold *metav1.Duration `json:"durationThreshold,omitempty"`
+}
+
+// NotificationSinkSpec defines the desired state of NotificationSink
+type NotificationSinkSpec struct {
+	// Type is the format of the notifications
+	// +kubebuilder:default=webhook
+	// +optional
+	Type SinkType `json:"type,omitempty"`
+
+	// URL is where the notifications are posted
+	// +optional
+	URL string `json:"url,omitempty"`
+
+	// URLSecretRef is a key in a Secret in the same namespace that holds the
+	// URL, this is used instead of url as Slack URLs should be kept secret
+	// +optional
+	URLSecretRef *corev1.SecretKeySelector `json:"urlSecretRef,omitempty"`
+
+	// Retries is the number of times that a failed delivery is retried, the
+	// backoff between them doubles from 1s up to 5m
+	// +kubebuilder:default=3
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=10
+	// +optional
+	Retries int `json:"retries,omitempty"`
+}
+
+// NotificationSinkStatus defines the observed state of NotificationSink
+type NotificationSinkStatus struct {
+	// LastDeliveryTime is when a notification was last sent
+	// +optional
+	LastDeliveryTime *metav1.Time `json:"lastDeliveryTime,omitempty"`
+
+	// LastReason is the reason for the last notification
+	// +optional
+	LastReason string `json:"lastReason,omitempty"`
+
+	// LastSource is the object that the last notification was about
+	// +optional
+	LastSource string `json:"lastSource,omitempty"`
+
+	// LastAttempts is how many attempts the last delivery took
+	// +optional
+	LastAttempts int `json:"lastAttempts,omitempty"`
+
+	// LastError is why the last delivery failed
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+
+	// Delivered is the number of notifications that have been delivered
+	// +optional
+	Delivered int64 `json:"delivered,omitempty"`
+
+	// Failed is the number of notifications that couldn't be delivered
+	// +optional
+	Failed int64 `json:"failed,omitempty"`
+
+	// Conditions are the latest observations of the state of the sink
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`
+//+kubebuilder:printcolumn:name="Delivered",type=string,JSONPath=`.status.conditions[?(@.type=="Delivered")].status`
+//+kubebuilder:printcolumn:name="Last",type=date,JSONPath=`.status.lastDeliveryTime`
+
+// NotificationSink is the Schema for the notificationsinks API
+type NotificationSink struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NotificationSinkSpec   `json:"spec,omitempty"`
+	Status NotificationSinkStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// NotificationSinkList contains a list of NotificationSink
+type NotificationSinkList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NotificationSink `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NotificationSink{}, &NotificationSinkList{})
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ProviderQuotaSpec defines the desired state of ProviderQuota
+type ProviderQuotaSpec struct {
+	// The quota is configured with flags on the controller, so there is
+	// nothing to set here
+}
+
+// ProviderQuotaStatus defines the observed state of ProviderQuota
+type ProviderQuotaStatus struct {
+	// QPS is the maximum rate of requests to the providers, zero is unlimited
+	// +optional
+	QPS float64 `json:"qps,omitempty"`
+
+	// DailyBudget is the number of requests allowed each day, zero is unlimited
+	// +optional
+	DailyBudget int `json:"dailyBudget,omitempty"`
+
+	// Used is the number of requests made since the budget was last reset
+	Used int `json:"used"`
+
+	// Remaining is the number of requests left in todays budget
+	// +optional
+	Remaining *int `json:"remaining,omitempty"`
+
+	// ResetTime is when the budget will next be reset
+	ResetTime metav1.Time `json:"resetTime"`
+
+	// LastUpdateTime is when the controller last updated this status
+	LastUpdateTime metav1.Time `json:"lastUpdateTime"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster,path=providerquotas,singular=providerquota
+//+kubebuilder:printcolumn:name="Budget",type=integer,JSONPath=`.status.dailyBudget`
+//+kubebuilder:printcolumn:name="Used",type=integer,JSONPath=`.status.used`
+//+kubebuilder:printcolumn:name="Remaining",type=integer,JSONPath=`.status.remaining`
+//+kubebuilder:printcolumn:name="Reset",type=string,JSONPath=`.status.resetTime`
+
+// ProviderQuota is the Schema for the providerquotas API, the controller
+// reports the usage of its provider budget here
+type ProviderQuota struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ProviderQuotaSpec   `json:"spec,omitempty"`
+	Status ProviderQuotaStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ProviderQuotaList contains a list of ProviderQuota
+type ProviderQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ProviderQuota `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ProviderQuota{}, &ProviderQuotaList{})
+}
@@ -0,0 +1,231 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ReachabilityCandidate is a destination that might be reachable from the
+// origin
+type ReachabilityCandidate struct {
+	// Name identifies the candidate in the ranking
+	Name string `json:"name"`
+
+	// Destination is the address of the candidate, either this or
+	// destinationRef needs to be set
+	// +optional
+	Destination string `json:"destination,omitempty"`
+
+	// DestinationRef is a Location in the same namespace to use as the
+	// candidate
+	// +optional
+	DestinationRef *corev1.LocalObjectReference `json:"destinationRef,omitempty"`
+}
+
+// ReachabilitySpec defines the desired state of Reachability
+type ReachabilitySpec struct {
+	// Origin is where every journey starts, either this or originRef needs to
+	// be set
+	// +optional
+	Origin string `json:"origin,omitempty"`
+
+	// OriginRef is a Location in the same namespace to use as the origin
+	// +optional
+	OriginRef *corev1.LocalObjectReference `json:"originRef,omitempty"`
+
+	// Candidates are the destinations that are ranked by how long it takes to
+	// reach them
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=25
+	// +listType=map
+	// +listMapKey=name
+	Candidates []ReachabilityCandidate `json:"candidates"`
+
+	// Budget is the longest that a journey can take for the candidate to be
+	// reachable, e.g. "30m"
+	Budget metav1.Duration `json:"budget"`
+
+	// Mode is how we will be travelling, defaults to driving
+	// +kubebuilder:default=driving
+	// +optional
+	Mode TravelMode `json:"mode,omitempty"`
+
+	// Avoid is a list of features that the journeys should stay away from
+	// +optional
+	Avoid []Avoid `json:"avoid,omitempty"`
+
+	// Provider is the routing backend to use, when it isn't set the default
+	// provider of the controller is used. Google builds a distance matrix,
+	// other providers find the route to each candidate
+	// +kubebuilder:validation:Enum=google;osrm
+	// +optional
+	Provider string `json:"provider,omitempty"`
+
+	// SecretRef is a Secret in the same namespace that holds the API key for
+	// the provider, when it isn't set the key configured on the controller is used
+	// +optional
+	SecretRef *corev1.SecretKeySelector `json:"secretRef,omitempty"`
+
+	// TrafficModel is the assumption used when calculating the duration in
+	// traffic, the journeys always leave when they are evaluated
+	// +optional
+	TrafficModel TrafficModel `json:"trafficModel,omitempty"`
+
+	// Language is the BCP 47 language of the addresses and durations
+	// +optional
+	Language string `json:"language,omitempty"`
+
+	// Units are used for the distances, they default to metric
+	// +optional
+	Units Units `json:"units,omitempty"`
+
+	// Schedule is a cron expression of when the candidates are ranked again,
+	// e.g. "*/15 7-19 * * 1-5", when it isn't set they are only ranked on changes
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+
+	// TimeZone is the IANA time zone of the schedule, e.g. "Europe/London",
+	// it defaults to UTC
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// RankedCandidate is how long it takes to reach a candidate
+type RankedCandidate struct {
+	// Name is the name of the candidate
+	Name string `json:"name"`
+
+	// Rank is the position of the candidate when ordered by travel time,
+	// starting at 1. It is zero when there is no journey to the candidate
+	// +optional
+	Rank int `json:"rank,omitempty"`
+
+	// Reachable is true when the journey fits within the budget
+	Reachable bool `json:"reachable"`
+
+	// Destination is the address of the candidate
+	// +optional
+	Destination string `json:"destination,omitempty"`
+
+	// ErrorCode is why there is no journey to the candidate, it is empty
+	// when a journey was found
+	// +optional
+	ErrorCode string `json:"errorCode,omitempty"`
+
+	// Distance is the human readable length of the journey
+	// +optional
+	Distance string `json:"distance,omitempty"`
+
+	// DistanceMeters is the length of the journey in meters
+	// +optional
+	DistanceMeters int `json:"distanceMeters,omitempty"`
+
+	// Duration is how long the journey is expected to take, in traffic when
+	// the provider returns it
+	// +optional
+	Duration string `json:"duration,omitempty"`
+
+	// DurationSeconds is how long the journey is expected to take in seconds
+	// +optional
+	DurationSeconds int64 `json:"durationSeconds,omitempty"`
+
+	// MarginSeconds is how much of the budget is left when the candidate is
+	// reached, it is negative when the journey takes longer than the budget
+	// +optional
+	MarginSeconds int64 `json:"marginSeconds,omitempty"`
+}
+
+// ReachabilityStatus defines the observed state of Reachability
+type ReachabilityStatus struct {
+	// Origin is the address of the origin
+	// +optional
+	Origin string `json:"origin,omitempty"`
+
+	// Candidates are ordered by how long it takes to reach them, candidates
+	// without a journey are last
+	// +optional
+	Candidates []RankedCandidate `json:"candidates,omitempty"`
+
+	// Reachable is the number of candidates that can be reached within the
+	// budget
+	// +optional
+	Reachable int `json:"reachable,omitempty"`
+
+	// Nearest is the name of the candidate that is quickest to reach
+	// +optional
+	Nearest string `json:"nearest,omitempty"`
+
+	// Error is why the candidates couldn't be ranked
+	// +optional
+	Error string `json:"error,omitempty"`
+
+	// SpecHash is a hash of the spec that was last ranked
+	// +optional
+	SpecHash string `json:"specHash,omitempty"`
+
+	// LastQueryTime is when the provider was last queried
+	// +optional
+	LastQueryTime *metav1.Time `json:"lastQueryTime,omitempty"`
+
+	// NextScheduleTime is when the candidates will next be ranked
+	// +optional
+	NextScheduleTime *metav1.Time `json:"nextScheduleTime,omitempty"`
+
+	// ObservedGeneration is the generation of the spec that the status describes
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions are the latest observations of the state of the Reachability
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:path=reachabilities,singular=reachability
+//+kubebuilder:printcolumn:name="Budget",type=string,JSONPath=`.spec.budget`
+//+kubebuilder:printcolumn:name="Reachable",type=integer,JSONPath=`.status.reachable`
+//+kubebuilder:printcolumn:name="Nearest",type=string,JSONPath=`.status.nearest`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="Last Query",type=date,JSONPath=`.status.lastQueryTime`
+
+// Reachability is the Schema for the reachabilities API, it ranks candidate
+// destinations by how long they take to reach from an origin
+type Reachability struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ReachabilitySpec   `json:"spec,omitempty"`
+	Status ReachabilityStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ReachabilityList contains a list of Reachability
+type ReachabilityList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Reachability `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Reachability{}, &ReachabilityList{})
+}
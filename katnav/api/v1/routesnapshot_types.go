@@ -0,0 +1,101 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SnapshotDirectionsLabel is the label that holds the name of the Directions
+// that a RouteSnapshot was recorded for
+const SnapshotDirectionsLabel = "katnav.fnnrn.me/directions"
+
+// RouteSnapshotSpec is the route that was found when the Directions was routed,
+// it is recorded by the controller and isn't expected to change
+type RouteSnapshotSpec struct {
+	// Directions is the name of the Directions that the route was found for
+	Directions string `json:"directions"`
+
+	// Time is when the route was found
+	Time metav1.Time `json:"time"`
+
+	// Summary gives a simple overview of the route
+	Summary string `json:"summary"`
+
+	// StartLocation is the address where the route begins
+	// +optional
+	StartLocation string `json:"startLocation,omitempty"`
+
+	// EndLocation is the address where the route ends
+	// +optional
+	EndLocation string `json:"endLocation,omitempty"`
+
+	// Distance is the total distance of the route
+	Distance string `json:"distance"`
+
+	// DistanceMeters is the total distance of the route in meters
+	DistanceMeters int `json:"distanceMeters"`
+
+	// Duration is the amount of time the route will take
+	Duration string `json:"duration"`
+
+	// DurationSeconds is the amount of time the route will take in seconds
+	DurationSeconds int64 `json:"durationSeconds"`
+
+	// DurationInTraffic is the amount of time the route will take in traffic
+	// +optional
+	DurationInTraffic string `json:"durationInTraffic,omitempty"`
+
+	// DurationInTrafficSeconds is the amount of time the route will take in
+	// traffic in seconds
+	// +optional
+	DurationInTrafficSeconds int64 `json:"durationInTrafficSeconds,omitempty"`
+
+	// OverviewPolyline is the encoded polyline of the whole route
+	// +optional
+	OverviewPolyline string `json:"overviewPolyline,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:printcolumn:name="Directions",type=string,JSONPath=`.spec.directions`
+//+kubebuilder:printcolumn:name="Time",type=date,JSONPath=`.spec.time`
+//+kubebuilder:printcolumn:name="Summary",type=string,JSONPath=`.spec.summary`
+//+kubebuilder:printcolumn:name="Distance",type=string,JSONPath=`.spec.distance`
+//+kubebuilder:printcolumn:name="Duration",type=string,JSONPath=`.spec.duration`
+//+kubebuilder:printcolumn:name="In Traffic",type=string,JSONPath=`.spec.durationInTraffic`,priority=1
+
+// RouteSnapshot is the Schema for the routesnapshots API, one is recorded by
+// the controller each time that a Directions is routed
+type RouteSnapshot struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec RouteSnapshotSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// RouteSnapshotList contains a list of RouteSnapshot
+type RouteSnapshotList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RouteSnapshot `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RouteSnapshot{}, &RouteSnapshotList{})
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+)
+
+// AnnotatedPosition returns the position in the position annotation of a
+// Trip, it is nil when the annotation isn't set
+func (t *Trip) AnnotatedPosition() (*LatLng, error) {
+	value, ok := t.Annotations[PositionAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	position, err := ParseLatLng(value)
+	if err != nil {
+		return nil, fmt.Errorf("annotation %s: %w", PositionAnnotation, err)
+	}
+	return &position, nil
+}
+
+// Position returns the latest position of a Trip, the annotation is used when
+// it has changed since it was last applied and differs from the status so that
+// an agent can use either of them. Changed is true when the position in the
+// status needs to be updated.
+func (t *Trip) Position() (position *TripPosition, changed bool, err error) {
+	if t.Annotations[PositionAnnotation] == t.Status.AppliedPositionAnnotation {
+		return t.Status.Position, false, nil
+	}
+	annotated, err := t.AnnotatedPosition()
+	if err != nil {
+		return t.Status.Position, false, err
+	}
+	if annotated != nil && (t.Status.Position == nil || t.Status.Position.LatLng != *annotated) {
+		return &TripPosition{LatLng: *annotated}, true, nil
+	}
+	return t.Status.Position, false, nil
+}
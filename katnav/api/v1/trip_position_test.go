@@ -0,0 +1,97 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTripPosition(t *testing.T) {
+	reported := &TripPosition{LatLng: LatLng{Lat: 51.5, Lng: -0.1}}
+	for _, test := range []struct {
+		name        string
+		annotations map[string]string
+		status      *TripPosition
+		applied     string
+		expected    *LatLng
+		changed     bool
+		err         bool
+	}{
+		{name: "no position"},
+		{name: "status", status: reported, expected: &reported.LatLng},
+		{
+			name:        "annotation",
+			annotations: map[string]string{PositionAnnotation: "51.48,-0.01"},
+			expected:    &LatLng{Lat: 51.48, Lng: -0.01},
+			changed:     true,
+		},
+		{
+			name:        "annotation moved",
+			annotations: map[string]string{PositionAnnotation: "51.48,-0.01"},
+			status:      reported,
+			expected:    &LatLng{Lat: 51.48, Lng: -0.01},
+			changed:     true,
+		},
+		{
+			name:        "annotation already in the status",
+			annotations: map[string]string{PositionAnnotation: " 51.5, -0.1"},
+			status:      reported,
+			expected:    &reported.LatLng,
+		},
+		{
+			name:        "annotation already applied",
+			annotations: map[string]string{PositionAnnotation: "51.48,-0.01"},
+			status:      reported,
+			applied:     "51.48,-0.01",
+			expected:    &reported.LatLng,
+		},
+		{
+			name:        "annotation changed since it was applied",
+			annotations: map[string]string{PositionAnnotation: "51.47,-0.02"},
+			status:      reported,
+			applied:     "51.48,-0.01",
+			expected:    &LatLng{Lat: 51.47, Lng: -0.02},
+			changed:     true,
+		},
+		{
+			name:        "invalid annotation",
+			annotations: map[string]string{PositionAnnotation: "Greenwich"},
+			status:      reported,
+			expected:    &reported.LatLng,
+			err:         true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			trip := &Trip{
+				ObjectMeta: metav1.ObjectMeta{Annotations: test.annotations},
+				Status:     TripStatus{Position: test.status, AppliedPositionAnnotation: test.applied},
+			}
+			position, changed, err := trip.Position()
+			if (err != nil) != test.err {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if changed != test.changed {
+				t.Errorf("expected changed to be %v", test.changed)
+			}
+			if (position == nil) != (test.expected == nil) || (position != nil && position.LatLng != *test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, position)
+			}
+		})
+	}
+}
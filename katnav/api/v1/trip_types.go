@@ -0,0 +1,214 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PositionAnnotation can be set to a "lat,lng" pair by an agent that isn't
+// able to patch the status of a Trip, it is copied to the status each time its
+// value changes
+const PositionAnnotation = "katnav.fnnrn.me/position"
+
+// ConditionArrived is true once the position of a Trip is within the arrival
+// radius of the destination
+const ConditionArrived = "Arrived"
+
+// TripSpec defines the desired state of Trip
+type TripSpec struct {
+	// Destination is where the trip ends, either this or destinationRef needs
+	// to be set
+	// +optional
+	Destination string `json:"destination,omitempty"`
+
+	// DestinationRef is a Location in the same namespace to use as the
+	// destination
+	// +optional
+	DestinationRef *corev1.LocalObjectReference `json:"destinationRef,omitempty"`
+
+	// Mode is how we will be travelling, defaults to driving
+	// +kubebuilder:default=driving
+	// +optional
+	Mode TravelMode `json:"mode,omitempty"`
+
+	// Avoid is a list of features that the route should stay away from
+	// +optional
+	Avoid []Avoid `json:"avoid,omitempty"`
+
+	// Provider is the routing backend to use, when it isn't set the default
+	// provider of the controller is used
+	// +kubebuilder:validation:Enum=google;osrm
+	// +optional
+	Provider string `json:"provider,omitempty"`
+
+	// SecretRef is a Secret in the same namespace that holds the API key for
+	// the provider, when it isn't set the key configured on the controller is used
+	// +optional
+	SecretRef *corev1.SecretKeySelector `json:"secretRef,omitempty"`
+
+	// TrafficModel is the assumption used when calculating the duration in
+	// traffic, the trip always leaves now
+	// +optional
+	TrafficModel TrafficModel `json:"trafficModel,omitempty"`
+
+	// Language is the BCP 47 language of the durations, e.g. "de" or "en-GB"
+	// +optional
+	Language string `json:"language,omitempty"`
+
+	// Region is the ccTLD region code that addresses are biased towards,
+	// e.g. "uk"
+	// +optional
+	Region string `json:"region,omitempty"`
+
+	// Units are used for the distances, they default to metric
+	// +optional
+	Units Units `json:"units,omitempty"`
+
+	// RecomputeDistanceMeters is how far the position has to move from where
+	// the route was last found before it is found again
+	// +kubebuilder:default=250
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	RecomputeDistanceMeters int32 `json:"recomputeDistanceMeters,omitempty"`
+
+	// MinRecomputeInterval is the shortest time between finding the route,
+	// movements within it are caught up with once it has passed
+	// +kubebuilder:default="1m"
+	// +optional
+	MinRecomputeInterval *metav1.Duration `json:"minRecomputeInterval,omitempty"`
+
+	// ArrivalRadiusMeters is how close the position has to be to the
+	// destination for the trip to have arrived
+	// +kubebuilder:default=100
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ArrivalRadiusMeters int32 `json:"arrivalRadiusMeters,omitempty"`
+}
+
+// TripPosition is where the vehicle on a trip was reported to be
+type TripPosition struct {
+	LatLng `json:",inline"`
+
+	// Time is when the position was reported, the controller sets it to when
+	// it saw the position if it isn't set
+	// +optional
+	Time *metav1.Time `json:"time,omitempty"`
+}
+
+// TripStatus defines the observed state of Trip
+type TripStatus struct {
+	// Position is the current position of the trip, it is set by the agent
+	// that tracks the vehicle with a status patch or the position annotation
+	// +optional
+	Position *TripPosition `json:"position,omitempty"`
+
+	// AppliedPositionAnnotation is the value of the position annotation that
+	// was last copied to the position, so that a later status patch isn't
+	// overwritten by an annotation that hasn't changed
+	// +optional
+	AppliedPositionAnnotation string `json:"appliedPositionAnnotation,omitempty"`
+
+	// RoutedPosition is the position that the route was last found from
+	// +optional
+	RoutedPosition *LatLng `json:"routedPosition,omitempty"`
+
+	// DestinationLocation is the coordinates of the destination, they are
+	// used for the arrival radius
+	// +optional
+	DestinationLocation *LatLng `json:"destinationLocation,omitempty"`
+
+	// RouteSummary is the summary of the remaining route
+	// +optional
+	RouteSummary string `json:"routeSummary,omitempty"`
+
+	// RemainingDistance is the human readable length of the remaining route
+	// +optional
+	RemainingDistance string `json:"remainingDistance,omitempty"`
+
+	// RemainingDistanceMeters is the length of the remaining route in meters
+	// +optional
+	RemainingDistanceMeters int `json:"remainingDistanceMeters,omitempty"`
+
+	// RemainingDuration is how long the remaining route is expected to take,
+	// in traffic when the provider returns it
+	// +optional
+	RemainingDuration string `json:"remainingDuration,omitempty"`
+
+	// RemainingDurationSeconds is how long the remaining route is expected to
+	// take in seconds
+	// +optional
+	RemainingDurationSeconds int64 `json:"remainingDurationSeconds,omitempty"`
+
+	// EstimatedArrivalTime is when the trip is expected to arrive
+	// +optional
+	EstimatedArrivalTime *metav1.Time `json:"estimatedArrivalTime,omitempty"`
+
+	// ArrivalTime is when the trip arrived
+	// +optional
+	ArrivalTime *metav1.Time `json:"arrivalTime,omitempty"`
+
+	// Error is why the route couldn't be found
+	// +optional
+	Error string `json:"error,omitempty"`
+
+	// LastQueryTime is when the provider was last queried
+	// +optional
+	LastQueryTime *metav1.Time `json:"lastQueryTime,omitempty"`
+
+	// ObservedGeneration is the generation of the spec that the status describes
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions are the latest observations of the state of the Trip
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Remaining",type=string,JSONPath=`.status.remainingDistance`
+//+kubebuilder:printcolumn:name="Duration",type=string,JSONPath=`.status.remainingDuration`
+//+kubebuilder:printcolumn:name="ETA",type=string,JSONPath=`.status.estimatedArrivalTime`
+//+kubebuilder:printcolumn:name="Arrived",type=string,JSONPath=`.status.conditions[?(@.type=="Arrived")].status`
+//+kubebuilder:printcolumn:name="Last Query",type=date,JSONPath=`.status.lastQueryTime`
+
+// Trip is the Schema for the trips API, it follows a vehicle to a fixed
+// destination and keeps the time that it will arrive up to date
+type Trip struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TripSpec   `json:"spec,omitempty"`
+	Status TripStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// TripList contains a list of Trip
+type TripList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Trip `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Trip{}, &TripList{})
+}
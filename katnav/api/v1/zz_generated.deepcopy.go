@@ -21,16 +21,176 @@ limitations under the License.
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlongRouteResult) DeepCopyInto(out *AlongRouteResult) {
+	*out = *in
+	if in.Places != nil {
+		in, out := &in.Places, &out.Places
+		*out = make([]RoutePlace, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlongRouteResult.
+func (in *AlongRouteResult) DeepCopy() *AlongRouteResult {
+	if in == nil {
+		return nil
+	}
+	out := new(AlongRouteResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlongRouteSearch) DeepCopyInto(out *AlongRouteSearch) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlongRouteSearch.
+func (in *AlongRouteSearch) DeepCopy() *AlongRouteSearch {
+	if in == nil {
+		return nil
+	}
+	out := new(AlongRouteSearch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Commute) DeepCopyInto(out *Commute) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Commute.
+func (in *Commute) DeepCopy() *Commute {
+	if in == nil {
+		return nil
+	}
+	out := new(Commute)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Commute) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CommuteList) DeepCopyInto(out *CommuteList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Commute, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommuteList.
+func (in *CommuteList) DeepCopy() *CommuteList {
+	if in == nil {
+		return nil
+	}
+	out := new(CommuteList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CommuteList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CommuteSpec) DeepCopyInto(out *CommuteSpec) {
+	*out = *in
+	in.JourneySpec.DeepCopyInto(&out.JourneySpec)
+	out.MaxDuration = in.MaxDuration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommuteSpec.
+func (in *CommuteSpec) DeepCopy() *CommuteSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CommuteSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CommuteStatus) DeepCopyInto(out *CommuteStatus) {
+	*out = *in
+	if in.Departure != nil {
+		in, out := &in.Departure, &out.Departure
+		*out = new(JourneyTime)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Arrival != nil {
+		in, out := &in.Arrival, &out.Arrival
+		*out = new(JourneyTime)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextScheduleTime != nil {
+		in, out := &in.NextScheduleTime, &out.NextScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.TravelTimes != nil {
+		in, out := &in.TravelTimes, &out.TravelTimes
+		*out = make([]TravelTime, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommuteStatus.
+func (in *CommuteStatus) DeepCopy() *CommuteStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CommuteStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Directions) DeepCopyInto(out *Directions) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Directions.
@@ -86,6 +246,65 @@ func (in *DirectionsList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DirectionsSpec) DeepCopyInto(out *DirectionsSpec) {
 	*out = *in
+	if in.SourceRef != nil {
+		in, out := &in.SourceRef, &out.SourceRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.DestinationRef != nil {
+		in, out := &in.DestinationRef, &out.DestinationRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.Avoid != nil {
+		in, out := &in.Avoid, &out.Avoid
+		*out = make([]Avoid, len(*in))
+		copy(*out, *in)
+	}
+	if in.Waypoints != nil {
+		in, out := &in.Waypoints, &out.Waypoints
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RefreshInterval != nil {
+		in, out := &in.RefreshInterval, &out.RefreshInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.ArrivalTime != nil {
+		in, out := &in.ArrivalTime, &out.ArrivalTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = new(Notifications)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Exports != nil {
+		in, out := &in.Exports, &out.Exports
+		*out = make([]ExportFormat, len(*in))
+		copy(*out, *in)
+	}
+	if in.Elevation != nil {
+		in, out := &in.Elevation, &out.Elevation
+		*out = new(ElevationSpec)
+		**out = **in
+	}
+	if in.AlongRoute != nil {
+		in, out := &in.AlongRoute, &out.AlongRoute
+		*out = make([]AlongRouteSearch, len(*in))
+		copy(*out, *in)
+	}
+	if in.SnapshotHistoryLimit != nil {
+		in, out := &in.SnapshotHistoryLimit, &out.SnapshotHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DirectionsSpec.
@@ -101,6 +320,58 @@ func (in *DirectionsSpec) DeepCopy() *DirectionsSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DirectionsStatus) DeepCopyInto(out *DirectionsStatus) {
 	*out = *in
+	if in.Fare != nil {
+		in, out := &in.Fare, &out.Fare
+		*out = new(Fare)
+		**out = **in
+	}
+	if in.Departure != nil {
+		in, out := &in.Departure, &out.Departure
+		*out = new(JourneyTime)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Arrival != nil {
+		in, out := &in.Arrival, &out.Arrival
+		*out = new(JourneyTime)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TravelTimes != nil {
+		in, out := &in.TravelTimes, &out.TravelTimes
+		*out = make([]TravelTime, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Routes != nil {
+		in, out := &in.Routes, &out.Routes
+		*out = make([]Route, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastQueryTime != nil {
+		in, out := &in.LastQueryTime, &out.LastQueryTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Elevation != nil {
+		in, out := &in.Elevation, &out.Elevation
+		*out = new(ElevationSummary)
+		**out = **in
+	}
+	if in.AlongRoute != nil {
+		in, out := &in.AlongRoute, &out.AlongRoute
+		*out = make([]AlongRouteResult, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DirectionsStatus.
@@ -112,3 +383,1233 @@ func (in *DirectionsStatus) DeepCopy() *DirectionsStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DistanceMatrix) DeepCopyInto(out *DistanceMatrix) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DistanceMatrix.
+func (in *DistanceMatrix) DeepCopy() *DistanceMatrix {
+	if in == nil {
+		return nil
+	}
+	out := new(DistanceMatrix)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DistanceMatrix) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DistanceMatrixList) DeepCopyInto(out *DistanceMatrixList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DistanceMatrix, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DistanceMatrixList.
+func (in *DistanceMatrixList) DeepCopy() *DistanceMatrixList {
+	if in == nil {
+		return nil
+	}
+	out := new(DistanceMatrixList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DistanceMatrixList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DistanceMatrixSpec) DeepCopyInto(out *DistanceMatrixSpec) {
+	*out = *in
+	if in.Origins != nil {
+		in, out := &in.Origins, &out.Origins
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Destinations != nil {
+		in, out := &in.Destinations, &out.Destinations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Modes != nil {
+		in, out := &in.Modes, &out.Modes
+		*out = make([]TravelMode, len(*in))
+		copy(*out, *in)
+	}
+	if in.Avoid != nil {
+		in, out := &in.Avoid, &out.Avoid
+		*out = make([]Avoid, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RefreshInterval != nil {
+		in, out := &in.RefreshInterval, &out.RefreshInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DistanceMatrixSpec.
+func (in *DistanceMatrixSpec) DeepCopy() *DistanceMatrixSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DistanceMatrixSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DistanceMatrixStatus) DeepCopyInto(out *DistanceMatrixStatus) {
+	*out = *in
+	if in.Matrices != nil {
+		in, out := &in.Matrices, &out.Matrices
+		*out = make([]Matrix, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastQueryTime != nil {
+		in, out := &in.LastQueryTime, &out.LastQueryTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DistanceMatrixStatus.
+func (in *DistanceMatrixStatus) DeepCopy() *DistanceMatrixStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DistanceMatrixStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElevationSpec) DeepCopyInto(out *ElevationSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElevationSpec.
+func (in *ElevationSpec) DeepCopy() *ElevationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElevationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElevationSummary) DeepCopyInto(out *ElevationSummary) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElevationSummary.
+func (in *ElevationSummary) DeepCopy() *ElevationSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(ElevationSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Fare) DeepCopyInto(out *Fare) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Fare.
+func (in *Fare) DeepCopy() *Fare {
+	if in == nil {
+		return nil
+	}
+	out := new(Fare)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JourneySpec) DeepCopyInto(out *JourneySpec) {
+	*out = *in
+	if in.SourceRef != nil {
+		in, out := &in.SourceRef, &out.SourceRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.DestinationRef != nil {
+		in, out := &in.DestinationRef, &out.DestinationRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.Avoid != nil {
+		in, out := &in.Avoid, &out.Avoid
+		*out = make([]Avoid, len(*in))
+		copy(*out, *in)
+	}
+	if in.Waypoints != nil {
+		in, out := &in.Waypoints, &out.Waypoints
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ArrivalTime != nil {
+		in, out := &in.ArrivalTime, &out.ArrivalTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = new(Notifications)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Exports != nil {
+		in, out := &in.Exports, &out.Exports
+		*out = make([]ExportFormat, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JourneySpec.
+func (in *JourneySpec) DeepCopy() *JourneySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(JourneySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JourneyTime) DeepCopyInto(out *JourneyTime) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JourneyTime.
+func (in *JourneyTime) DeepCopy() *JourneyTime {
+	if in == nil {
+		return nil
+	}
+	out := new(JourneyTime)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LatLng) DeepCopyInto(out *LatLng) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LatLng.
+func (in *LatLng) DeepCopy() *LatLng {
+	if in == nil {
+		return nil
+	}
+	out := new(LatLng)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Leg) DeepCopyInto(out *Leg) {
+	*out = *in
+	out.StartCoordinates = in.StartCoordinates
+	out.EndCoordinates = in.EndCoordinates
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]Step, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Leg.
+func (in *Leg) DeepCopy() *Leg {
+	if in == nil {
+		return nil
+	}
+	out := new(Leg)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Location) DeepCopyInto(out *Location) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Location.
+func (in *Location) DeepCopy() *Location {
+	if in == nil {
+		return nil
+	}
+	out := new(Location)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Location) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocationList) DeepCopyInto(out *LocationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Location, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocationList.
+func (in *LocationList) DeepCopy() *LocationList {
+	if in == nil {
+		return nil
+	}
+	out := new(LocationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LocationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocationSpec) DeepCopyInto(out *LocationSpec) {
+	*out = *in
+	if in.Coordinates != nil {
+		in, out := &in.Coordinates, &out.Coordinates
+		*out = new(LatLng)
+		**out = **in
+	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocationSpec.
+func (in *LocationSpec) DeepCopy() *LocationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LocationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocationStatus) DeepCopyInto(out *LocationStatus) {
+	*out = *in
+	if in.Coordinates != nil {
+		in, out := &in.Coordinates, &out.Coordinates
+		*out = new(LatLng)
+		**out = **in
+	}
+	if in.LastResolveTime != nil {
+		in, out := &in.LastResolveTime, &out.LastResolveTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocationStatus.
+func (in *LocationStatus) DeepCopy() *LocationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LocationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Matrix) DeepCopyInto(out *Matrix) {
+	*out = *in
+	if in.Rows != nil {
+		in, out := &in.Rows, &out.Rows
+		*out = make([]MatrixRow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Matrix.
+func (in *Matrix) DeepCopy() *Matrix {
+	if in == nil {
+		return nil
+	}
+	out := new(Matrix)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatrixElement) DeepCopyInto(out *MatrixElement) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatrixElement.
+func (in *MatrixElement) DeepCopy() *MatrixElement {
+	if in == nil {
+		return nil
+	}
+	out := new(MatrixElement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatrixRow) DeepCopyInto(out *MatrixRow) {
+	*out = *in
+	if in.Elements != nil {
+		in, out := &in.Elements, &out.Elements
+		*out = make([]MatrixElement, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatrixRow.
+func (in *MatrixRow) DeepCopy() *MatrixRow {
+	if in == nil {
+		return nil
+	}
+	out := new(MatrixRow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationSink) DeepCopyInto(out *NotificationSink) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationSink.
+func (in *NotificationSink) DeepCopy() *NotificationSink {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NotificationSink) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationSinkList) DeepCopyInto(out *NotificationSinkList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NotificationSink, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationSinkList.
+func (in *NotificationSinkList) DeepCopy() *NotificationSinkList {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationSinkList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NotificationSinkList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationSinkSpec) DeepCopyInto(out *NotificationSinkSpec) {
+	*out = *in
+	if in.URLSecretRef != nil {
+		in, out := &in.URLSecretRef, &out.URLSecretRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationSinkSpec.
+func (in *NotificationSinkSpec) DeepCopy() *NotificationSinkSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationSinkSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationSinkStatus) DeepCopyInto(out *NotificationSinkStatus) {
+	*out = *in
+	if in.LastDeliveryTime != nil {
+		in, out := &in.LastDeliveryTime, &out.LastDeliveryTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationSinkStatus.
+func (in *NotificationSinkStatus) DeepCopy() *NotificationSinkStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationSinkStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Notifications) DeepCopyInto(out *Notifications) {
+	*out = *in
+	if in.Sinks != nil {
+		in, out := &in.Sinks, &out.Sinks
+		*out = make([]corev1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.DurationThreshold != nil {
+		in, out := &in.DurationThreshold, &out.DurationThreshold
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Notifications.
+func (in *Notifications) DeepCopy() *Notifications {
+	if in == nil {
+		return nil
+	}
+	out := new(Notifications)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProviderQuota) DeepCopyInto(out *ProviderQuota) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderQuota.
+func (in *ProviderQuota) DeepCopy() *ProviderQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(ProviderQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ProviderQuota) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProviderQuotaList) DeepCopyInto(out *ProviderQuotaList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ProviderQuota, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderQuotaList.
+func (in *ProviderQuotaList) DeepCopy() *ProviderQuotaList {
+	if in == nil {
+		return nil
+	}
+	out := new(ProviderQuotaList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ProviderQuotaList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProviderQuotaSpec) DeepCopyInto(out *ProviderQuotaSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderQuotaSpec.
+func (in *ProviderQuotaSpec) DeepCopy() *ProviderQuotaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProviderQuotaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProviderQuotaStatus) DeepCopyInto(out *ProviderQuotaStatus) {
+	*out = *in
+	if in.Remaining != nil {
+		in, out := &in.Remaining, &out.Remaining
+		*out = new(int)
+		**out = **in
+	}
+	in.ResetTime.DeepCopyInto(&out.ResetTime)
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderQuotaStatus.
+func (in *ProviderQuotaStatus) DeepCopy() *ProviderQuotaStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ProviderQuotaStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RankedCandidate) DeepCopyInto(out *RankedCandidate) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RankedCandidate.
+func (in *RankedCandidate) DeepCopy() *RankedCandidate {
+	if in == nil {
+		return nil
+	}
+	out := new(RankedCandidate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Reachability) DeepCopyInto(out *Reachability) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Reachability.
+func (in *Reachability) DeepCopy() *Reachability {
+	if in == nil {
+		return nil
+	}
+	out := new(Reachability)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Reachability) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReachabilityCandidate) DeepCopyInto(out *ReachabilityCandidate) {
+	*out = *in
+	if in.DestinationRef != nil {
+		in, out := &in.DestinationRef, &out.DestinationRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReachabilityCandidate.
+func (in *ReachabilityCandidate) DeepCopy() *ReachabilityCandidate {
+	if in == nil {
+		return nil
+	}
+	out := new(ReachabilityCandidate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReachabilityList) DeepCopyInto(out *ReachabilityList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Reachability, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReachabilityList.
+func (in *ReachabilityList) DeepCopy() *ReachabilityList {
+	if in == nil {
+		return nil
+	}
+	out := new(ReachabilityList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ReachabilityList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReachabilitySpec) DeepCopyInto(out *ReachabilitySpec) {
+	*out = *in
+	if in.OriginRef != nil {
+		in, out := &in.OriginRef, &out.OriginRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.Candidates != nil {
+		in, out := &in.Candidates, &out.Candidates
+		*out = make([]ReachabilityCandidate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	out.Budget = in.Budget
+	if in.Avoid != nil {
+		in, out := &in.Avoid, &out.Avoid
+		*out = make([]Avoid, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReachabilitySpec.
+func (in *ReachabilitySpec) DeepCopy() *ReachabilitySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ReachabilitySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReachabilityStatus) DeepCopyInto(out *ReachabilityStatus) {
+	*out = *in
+	if in.Candidates != nil {
+		in, out := &in.Candidates, &out.Candidates
+		*out = make([]RankedCandidate, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastQueryTime != nil {
+		in, out := &in.LastQueryTime, &out.LastQueryTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextScheduleTime != nil {
+		in, out := &in.NextScheduleTime, &out.NextScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReachabilityStatus.
+func (in *ReachabilityStatus) DeepCopy() *ReachabilityStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReachabilityStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Route) DeepCopyInto(out *Route) {
+	*out = *in
+	if in.Fare != nil {
+		in, out := &in.Fare, &out.Fare
+		*out = new(Fare)
+		**out = **in
+	}
+	if in.Warnings != nil {
+		in, out := &in.Warnings, &out.Warnings
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.WaypointOrder != nil {
+		in, out := &in.WaypointOrder, &out.WaypointOrder
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
+	if in.Legs != nil {
+		in, out := &in.Legs, &out.Legs
+		*out = make([]Leg, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Route.
+func (in *Route) DeepCopy() *Route {
+	if in == nil {
+		return nil
+	}
+	out := new(Route)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoutePlace) DeepCopyInto(out *RoutePlace) {
+	*out = *in
+	out.Location = in.Location
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoutePlace.
+func (in *RoutePlace) DeepCopy() *RoutePlace {
+	if in == nil {
+		return nil
+	}
+	out := new(RoutePlace)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouteSnapshot) DeepCopyInto(out *RouteSnapshot) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouteSnapshot.
+func (in *RouteSnapshot) DeepCopy() *RouteSnapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(RouteSnapshot)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RouteSnapshot) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouteSnapshotList) DeepCopyInto(out *RouteSnapshotList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RouteSnapshot, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouteSnapshotList.
+func (in *RouteSnapshotList) DeepCopy() *RouteSnapshotList {
+	if in == nil {
+		return nil
+	}
+	out := new(RouteSnapshotList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RouteSnapshotList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouteSnapshotSpec) DeepCopyInto(out *RouteSnapshotSpec) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouteSnapshotSpec.
+func (in *RouteSnapshotSpec) DeepCopy() *RouteSnapshotSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RouteSnapshotSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Step) DeepCopyInto(out *Step) {
+	*out = *in
+	out.StartLocation = in.StartLocation
+	out.EndLocation = in.EndLocation
+	if in.Transit != nil {
+		in, out := &in.Transit, &out.Transit
+		*out = new(TransitDetails)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Step.
+func (in *Step) DeepCopy() *Step {
+	if in == nil {
+		return nil
+	}
+	out := new(Step)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransitDetails) DeepCopyInto(out *TransitDetails) {
+	*out = *in
+	if in.Agencies != nil {
+		in, out := &in.Agencies, &out.Agencies
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.DepartureStop = in.DepartureStop
+	out.ArrivalStop = in.ArrivalStop
+	if in.DepartureTime != nil {
+		in, out := &in.DepartureTime, &out.DepartureTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ArrivalTime != nil {
+		in, out := &in.ArrivalTime, &out.ArrivalTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransitDetails.
+func (in *TransitDetails) DeepCopy() *TransitDetails {
+	if in == nil {
+		return nil
+	}
+	out := new(TransitDetails)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransitStop) DeepCopyInto(out *TransitStop) {
+	*out = *in
+	out.Location = in.Location
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransitStop.
+func (in *TransitStop) DeepCopy() *TransitStop {
+	if in == nil {
+		return nil
+	}
+	out := new(TransitStop)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TravelTime) DeepCopyInto(out *TravelTime) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TravelTime.
+func (in *TravelTime) DeepCopy() *TravelTime {
+	if in == nil {
+		return nil
+	}
+	out := new(TravelTime)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Trip) DeepCopyInto(out *Trip) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Trip.
+func (in *Trip) DeepCopy() *Trip {
+	if in == nil {
+		return nil
+	}
+	out := new(Trip)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Trip) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TripList) DeepCopyInto(out *TripList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Trip, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TripList.
+func (in *TripList) DeepCopy() *TripList {
+	if in == nil {
+		return nil
+	}
+	out := new(TripList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TripList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TripPosition) DeepCopyInto(out *TripPosition) {
+	*out = *in
+	out.LatLng = in.LatLng
+	if in.Time != nil {
+		in, out := &in.Time, &out.Time
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TripPosition.
+func (in *TripPosition) DeepCopy() *TripPosition {
+	if in == nil {
+		return nil
+	}
+	out := new(TripPosition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TripSpec) DeepCopyInto(out *TripSpec) {
+	*out = *in
+	if in.DestinationRef != nil {
+		in, out := &in.DestinationRef, &out.DestinationRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.Avoid != nil {
+		in, out := &in.Avoid, &out.Avoid
+		*out = make([]Avoid, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MinRecomputeInterval != nil {
+		in, out := &in.MinRecomputeInterval, &out.MinRecomputeInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TripSpec.
+func (in *TripSpec) DeepCopy() *TripSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TripSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TripStatus) DeepCopyInto(out *TripStatus) {
+	*out = *in
+	if in.Position != nil {
+		in, out := &in.Position, &out.Position
+		*out = new(TripPosition)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RoutedPosition != nil {
+		in, out := &in.RoutedPosition, &out.RoutedPosition
+		*out = new(LatLng)
+		**out = **in
+	}
+	if in.DestinationLocation != nil {
+		in, out := &in.DestinationLocation, &out.DestinationLocation
+		*out = new(LatLng)
+		**out = **in
+	}
+	if in.EstimatedArrivalTime != nil {
+		in, out := &in.EstimatedArrivalTime, &out.EstimatedArrivalTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ArrivalTime != nil {
+		in, out := &in.ArrivalTime, &out.ArrivalTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastQueryTime != nil {
+		in, out := &in.LastQueryTime, &out.LastQueryTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TripStatus.
+func (in *TripStatus) DeepCopy() *TripStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TripStatus)
+	in.DeepCopyInto(out)
+	return out
+}
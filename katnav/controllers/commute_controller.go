@@ -0,0 +1,321 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/notify"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/provider"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/routecache"
+)
+
+// commuteMaxAge is how old a cached route can be for a commute, commutes that
+// share a journey and a schedule will share a query
+const commuteMaxAge = 5 * time.Minute
+
+// CommuteReconciler reconciles a Commute object
+type CommuteReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	Recorder  record.EventRecorder
+	Providers *Providers
+	Cache     *routecache.Cache
+	Notifier  *Notifier
+}
+
+//+kubebuilder:rbac:groups=katnav.fnnrn.me,resources=commutes,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=katnav.fnnrn.me,resources=commutes/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=katnav.fnnrn.me,resources=commutes/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Reconcile queries the journey of a Commute each time that it is scheduled
+// and raises an event when it is going to take longer than the maximum
+func (r *CommuteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	var commute katnavv1.Commute
+	if err := r.Get(ctx, req.NamespacedName, &commute); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "unable to fetch Commute object")
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	schedule, err := parseSchedule(commute.Spec.Schedule, commute.Spec.TimeZone)
+	if err != nil {
+		commute.Status.Error = err.Error()
+		commute.Status.NextScheduleTime = nil
+		setCondition(&commute.Status.Conditions, commute.Generation, katnavv1.ConditionReady, false, "InvalidSchedule", err.Error())
+		// Nothing will change until the spec is fixed
+		return ctrl.Result{}, r.updateStatus(ctx, &commute)
+	}
+	if commute.Spec.Suspend {
+		commute.Status.NextScheduleTime = nil
+		setCondition(&commute.Status.Conditions, commute.Generation, katnavv1.ConditionReady, false, "Suspended", "")
+		return ctrl.Result{}, r.updateStatus(ctx, &commute)
+	}
+
+	now := time.Now()
+	last := commute.CreationTimestamp.Time
+	if commute.Status.LastScheduleTime != nil {
+		last = commute.Status.LastScheduleTime.Time
+	}
+	if next := schedule.Next(last); next.After(now) {
+		log.Info("Commute isn't due", "Next", next)
+		if commute.Status.NextScheduleTime == nil || !commute.Status.NextScheduleTime.Time.Equal(next) || commute.Status.ObservedGeneration != commute.Generation {
+			nextTime := metav1.NewTime(next)
+			commute.Status.NextScheduleTime = &nextTime
+			// A schedule that has been fixed or resumed is waiting again
+			if ready := meta.FindStatusCondition(commute.Status.Conditions, katnavv1.ConditionReady); ready == nil || ready.Reason == "InvalidSchedule" || ready.Reason == "Suspended" {
+				commute.Status.Error = ""
+				setCondition(&commute.Status.Conditions, commute.Generation, katnavv1.ConditionReady, false, "Scheduled", "waiting for the next journey")
+			}
+			if err = r.updateStatus(ctx, &commute); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{RequeueAfter: next.Sub(now)}, nil
+	}
+
+	log.Info("Determining commute", "Source", commute.Spec.Source, "Destination", commute.Spec.Destination)
+	// The commute leaves now, so ask for the duration in traffic unless a
+	// specific time has been asked for
+	spec, err := resolveLocations(ctx, r.Client, commute.Namespace, commute.Spec.DirectionsSpec())
+	if err != nil {
+		var locationErr *locationError
+		if !goerrors.As(err, &locationErr) {
+			return ctrl.Result{}, err
+		}
+		return r.journeyError(ctx, &commute, schedule, &provider.Error{Reason: locationErr.reason, Err: err})
+	}
+	spec.RefreshInterval = nil
+	if spec.DepartureTime == "" && spec.ArrivalTime == nil {
+		spec.DepartureTime = katnavv1.DepartureTimeNow
+	}
+	result, err := findRoutes(ctx, r.Providers, r.Cache, commute.Namespace, &spec, commuteMaxAge)
+	if err == nil && len(result.Routes) == 0 {
+		err = &provider.Error{Reason: provider.ReasonZeroResults, Err: goerrors.New("no route could be found between the source and destination")}
+	}
+	if err != nil {
+		return r.journeyError(ctx, &commute, schedule, err)
+	}
+
+	scheduled := metav1.NewTime(now)
+	commute.Status.LastScheduleTime = &scheduled
+	nextTime := metav1.NewTime(schedule.Next(now))
+	commute.Status.NextScheduleTime = &nextTime
+
+	route := result.Routes[0]
+	var events []notify.Event
+	if commute.Status.RouteSummary != "" && commute.Status.RouteSummary != route.Summary {
+		event := newEvent("Commute", &commute, notify.ReasonRouteChanged, fmt.Sprintf("route changed from %s to %s", commute.Status.RouteSummary, route.Summary))
+		event.PreviousSummary = commute.Status.RouteSummary
+		events = append(events, event)
+	}
+	duration, expected := journeyDuration(route)
+	commute.Status.RouteSummary = route.Summary
+	commute.Status.Distance = route.Distance
+	commute.Status.Duration = duration
+	commute.Status.DurationSeconds = int64(expected.Seconds())
+	commute.Status.TravelTimes = recordTravelTime(commute.Status.TravelTimes, katnavv1.TravelTime{
+		Time:                     scheduled,
+		DurationSeconds:          route.DurationSeconds,
+		DurationInTrafficSeconds: route.DurationInTrafficSeconds,
+	})
+	commute.Status.Error = ""
+	withinQuota(&commute.Status.Conditions, commute.Generation)
+	setCondition(&commute.Status.Conditions, commute.Generation, katnavv1.ConditionReady, true, "RouteFound", "")
+
+	wasDelayed := meta.IsStatusConditionTrue(commute.Status.Conditions, katnavv1.ConditionDelayExceeded)
+	if maxDuration := commute.Spec.MaxDuration.Duration; maxDuration > 0 && expected > maxDuration {
+		delay := expected - maxDuration
+		message := fmt.Sprintf("commute is expected to take %s, which is %s longer than %s", duration, delay, maxDuration)
+		commute.Status.Delay = delay.String()
+		setCondition(&commute.Status.Conditions, commute.Generation, katnavv1.ConditionDelayExceeded, true, "MaxDurationExceeded", message)
+		r.Recorder.Event(&commute, corev1.EventTypeWarning, "DelayExceeded", message)
+		if !wasDelayed {
+			events = append(events, newEvent("Commute", &commute, notify.ReasonDelayExceeded, message))
+		}
+	} else {
+		if wasDelayed {
+			message := fmt.Sprintf("commute is expected to take %s", duration)
+			r.Recorder.Event(&commute, corev1.EventTypeNormal, "DelayCleared", message)
+			events = append(events, newEvent("Commute", &commute, notify.ReasonDelayCleared, message))
+		}
+		commute.Status.Delay = ""
+		setCondition(&commute.Status.Conditions, commute.Generation, katnavv1.ConditionDelayExceeded, false, "WithinMaxDuration", "")
+	}
+	log.Info("Commute", "Summary", route.Summary, "Duration", duration, "Delay", commute.Status.Delay)
+
+	// A missing time zone only loses the local times, so it waits for the
+	// next schedule rather than being retried
+	commute.Status.Departure, commute.Status.Arrival, err = journeyTimes(ctx, r.Providers, commute.Namespace, &spec, route, departureTime(&spec, route, now))
+	if err != nil {
+		reason, _ := provider.ReasonFor(err)
+		if reason != reasonTimeZonesUnavailable {
+			log.Error(err, "unable to resolve time zones", "Reason", reason)
+		}
+		setCondition(&commute.Status.Conditions, commute.Generation, katnavv1.ConditionTimeZonesResolved, false, reason, err.Error())
+	} else {
+		setCondition(&commute.Status.Conditions, commute.Generation, katnavv1.ConditionTimeZonesResolved, true, "TimeZonesResolved", "")
+	}
+
+	commute.Status.ExportConfigMap = exportRoutes(ctx, r.Client, r.Scheme, &commute, commute.Spec.Exports, result.Routes, &commute.Status.Conditions)
+
+	if err = r.updateStatus(ctx, &commute); err != nil {
+		return ctrl.Result{}, err
+	}
+	for x := range events {
+		events[x].Summary = route.Summary
+		events[x].Duration = duration
+		events[x].DurationSeconds = commute.Status.DurationSeconds
+		r.Notifier.Notify(ctx, commute.Namespace, commute.Spec.Notifications, events[x])
+	}
+	return ctrl.Result{RequeueAfter: nextTime.Sub(now)}, nil
+}
+
+// journeyError records why a scheduled journey couldn't be found. Transient
+// errors are retried with a backoff and an exhausted quota once it is reset,
+// anything else waits for the next schedule
+func (r *CommuteReconciler) journeyError(ctx context.Context, commute *katnavv1.Commute, schedule cron.Schedule, err error) (ctrl.Result, error) {
+	reason, transient := provider.ReasonFor(err)
+	log.FromContext(ctx).Error(err, "unable to fetch Commute", "Reason", reason, "Transient", transient)
+
+	commute.Status.Error = err.Error()
+	setCondition(&commute.Status.Conditions, commute.Generation, katnavv1.ConditionReady, false, reason, err.Error())
+	r.Recorder.Event(commute, corev1.EventTypeWarning, reason, err.Error())
+	if reset, exhausted := quotaExhausted(&commute.Status.Conditions, commute.Generation, err); exhausted {
+		if updateErr := r.updateStatus(ctx, commute); updateErr != nil {
+			return ctrl.Result{}, updateErr
+		}
+		return ctrl.Result{RequeueAfter: reset}, nil
+	}
+	if transient {
+		if updateErr := r.updateStatus(ctx, commute); updateErr != nil {
+			return ctrl.Result{}, updateErr
+		}
+		return ctrl.Result{}, err
+	}
+
+	now := time.Now()
+	scheduled := metav1.NewTime(now)
+	commute.Status.LastScheduleTime = &scheduled
+	nextTime := metav1.NewTime(schedule.Next(now))
+	commute.Status.NextScheduleTime = &nextTime
+	return ctrl.Result{RequeueAfter: nextTime.Sub(now)}, r.updateStatus(ctx, commute)
+}
+
+// updateStatus writes the status of the Commute for the generation it describes
+func (r *CommuteReconciler) updateStatus(ctx context.Context, commute *katnavv1.Commute) error {
+	commute.Status.ObservedGeneration = commute.Generation
+	err := r.Client.Status().Update(ctx, commute, &client.UpdateOptions{})
+	if err != nil {
+		log.FromContext(ctx).Error(err, "unable to update commute")
+	}
+	return err
+}
+
+// parseSchedule parses a cron schedule in a time zone, which defaults to UTC
+func parseSchedule(spec, timeZone string) (cron.Schedule, error) {
+	location := time.UTC
+	if timeZone != "" {
+		var err error
+		location, err = time.LoadLocation(timeZone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %w", timeZone, err)
+		}
+	}
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: %w", spec, err)
+	}
+	if specSchedule, ok := schedule.(*cron.SpecSchedule); ok {
+		specSchedule.Location = location
+	}
+	return schedule, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *CommuteReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&katnavv1.Commute{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(r.secretToCommutes)).
+		Watches(&source.Kind{Type: &katnavv1.Location{}}, handler.EnqueueRequestsFromMapFunc(r.locationToCommutes)).
+		Complete(r)
+}
+
+// secretToCommutes finds every Commute that uses the API key in a Secret, so
+// that a journey that failed without it is found once it is created or rotated
+func (r *CommuteReconciler) secretToCommutes(obj client.Object) []reconcile.Request {
+	var commutes katnavv1.CommuteList
+	if err := r.List(context.TODO(), &commutes); err != nil {
+		return nil
+	}
+	secret := types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+	var requests []reconcile.Request
+	for x := range commutes.Items {
+		commute := &commutes.Items[x]
+		if r.Providers.UsesSecret(commute.Spec.Provider, commute.Namespace, commute.Spec.SecretRef, secret) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: commute.Namespace, Name: commute.Name},
+			})
+		}
+	}
+	return requests
+}
+
+// locationToCommutes finds every Commute that references a Location, so that
+// the next journey uses its new coordinates
+func (r *CommuteReconciler) locationToCommutes(obj client.Object) []reconcile.Request {
+	var commutes katnavv1.CommuteList
+	if err := r.List(context.TODO(), &commutes, client.InNamespace(obj.GetNamespace())); err != nil {
+		return nil
+	}
+	var requests []reconcile.Request
+	for x := range commutes.Items {
+		commute := &commutes.Items[x]
+		if refersTo(commute.Spec.SourceRef, obj.GetName()) || refersTo(commute.Spec.DestinationRef, obj.GetName()) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: commute.Namespace, Name: commute.Name},
+			})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	goerrors "errors"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/provider"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/quota"
+)
+
+// setCondition adds or updates a condition, the transition time only changes
+// when the status of the condition does
+func setCondition(conditions *[]metav1.Condition, generation int64, conditionType string, status bool, reason, message string) {
+	condition := metav1.Condition{
+		Type:               conditionType,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: generation,
+		Reason:             reason,
+		Message:            message,
+	}
+	if status {
+		condition.Status = metav1.ConditionTrue
+	}
+	meta.SetStatusCondition(conditions, condition)
+}
+
+// quotaExhausted sets the QuotaExhausted condition when a request failed
+// because the daily budget has run out. Running out of budget isn't a problem
+// with the provider, so the caller waits until the returned reset before
+// trying again rather than backing off.
+func quotaExhausted(conditions *[]metav1.Condition, generation int64, err error) (time.Duration, bool) {
+	var exhausted *quota.ExhaustedError
+	if !goerrors.As(err, &exhausted) {
+		return 0, false
+	}
+	reason, _ := provider.ReasonFor(err)
+	setCondition(conditions, generation, katnavv1.ConditionQuotaExhausted, true, reason, err.Error())
+	return time.Until(exhausted.ResetTime), true
+}
+
+// withinQuota clears the QuotaExhausted condition after a request succeeded
+func withinQuota(conditions *[]metav1.Condition, generation int64) {
+	setCondition(conditions, generation, katnavv1.ConditionQuotaExhausted, false, "WithinQuota", "")
+}
@@ -18,32 +18,51 @@ package controllers
 
 import (
 	"context"
+	goerrors "errors"
 	"fmt"
+	"reflect"
+	"time"
 
-	strip "github.com/grokify/html-strip-tags-go"
 	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
-	"googlemaps.github.io/maps"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/notify"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/provider"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/routecache"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
-	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/client-go/kubernetes"
-	restClient "k8s.io/client-go/rest"
-	cmdClient "k8s.io/client-go/tools/clientcmd"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 // DirectionsReconciler reconciles a Directions object
 type DirectionsReconciler struct {
 	client.Client
-	Scheme  *runtime.Scheme
-	mClient *maps.Client
+	Scheme *runtime.Scheme
+
+	// Providers are the routing backends that are available
+	Providers *Providers
+	// Cache holds routes shared between Directions, it is optional
+	Cache *routecache.Cache
+	// Notifier sends notifications when the route changes
+	Notifier *Notifier
 }
 
 //+kubebuilder:rbac:groups=katnav.fnnrn.me,resources=directions,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=katnav.fnnrn.me,resources=directions/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=katnav.fnnrn.me,resources=directions/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups=katnav.fnnrn.me,resources=locations,verbs=get;list;watch
+//+kubebuilder:rbac:groups=katnav.fnnrn.me,resources=routesnapshots,verbs=get;list;watch;create;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -75,79 +94,273 @@ func (r *DirectionsReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	// your logic here
 	log.Info("Determining journey", "Source", directions.Spec.Source, "Destination", directions.Spec.Destination)
 
-	request := &maps.DirectionsRequest{
-		Origin:      directions.Spec.Source,
-		Destination: directions.Spec.Destination,
-		Mode:        maps.TravelModeDriving,
+	// Any Locations are resolved first, so that a Location that moves changes
+	// the hash below and the route is found again
+	spec, err := resolveLocations(ctx, r.Client, directions.Namespace, directions.Spec)
+	if err != nil {
+		var locationErr *locationError
+		if !goerrors.As(err, &locationErr) {
+			return ctrl.Result{}, err
+		}
+		log.Info("Unable to resolve journey", "Reason", locationErr.reason, "Error", err.Error())
+		directions.Status.Error = err.Error()
+		setCondition(&directions.Status.Conditions, directions.Generation, katnavv1.ConditionReady, false, locationErr.reason, err.Error())
+		return ctrl.Result{}, r.updateStatus(ctx, &directions)
 	}
 
-	route, _, err := r.mClient.Directions(context.Background(), request)
+	// Queries can cost money, so only ask the provider again if the spec has
+	// changed, the last attempt failed or the route is due to be refreshed
+	hash, err := specHash(spec)
 	if err != nil {
-		log.Error(err, "unable to fetch Directions")
+		return ctrl.Result{}, err
 	}
-	var directionsString string
-	if len(route) == 0 {
-		return ctrl.Result{}, nil
+	if hash == directions.Status.SpecHash && meta.IsStatusConditionTrue(directions.Status.Conditions, katnavv1.ConditionReady) {
+		refresh := refreshAfter(directions.Spec.RefreshInterval, directions.Status.LastQueryTime)
+		if directions.Spec.RefreshInterval == nil || refresh > 0 {
+			log.Info("Route is up to date", "Refresh", refresh)
+			if enrichmentDue(&directions) && len(directions.Status.Routes) != 0 {
+				retry := r.enrichRoute(ctx, &directions, directions.Status.Routes[0])
+				if err := r.updateStatus(ctx, &directions); err != nil {
+					return ctrl.Result{}, err
+				}
+				refresh = soonest(refresh, retry)
+			}
+			return ctrl.Result{RequeueAfter: refresh}, nil
+		}
 	}
+	directions.Status.SpecHash = hash
+	now := metav1.Now()
+	directions.Status.LastQueryTime = &now
 
-	log.Info("New Route", "Summary", route[0].Summary)
-	for x := range route[0].Legs {
-		for y := range route[0].Legs[x].Steps {
-			stripped := strip.StripTags(route[0].Legs[x].Steps[y].HTMLInstructions)
-			directionsString += stripped + "\n"
+	// A refresh needs a route that is newer than the refresh interval
+	var maxAge time.Duration
+	if directions.Spec.RefreshInterval != nil {
+		maxAge = directions.Spec.RefreshInterval.Duration
+	}
+	result, err := findRoutes(ctx, r.Providers, r.Cache, directions.Namespace, &spec, maxAge)
+	if err != nil {
+		return r.providerError(ctx, &directions, err)
+	}
+	queried := metav1.NewTime(result.Created)
+	directions.Status.LastQueryTime = &queried
+	directions.Status.FromCache = result.FromCache
+	route := result.Routes
+
+	if len(route) == 0 {
+		log.Info("No route found")
+		directions.Status = katnavv1.DirectionsStatus{
+			SpecHash:      directions.Status.SpecHash,
+			LastQueryTime: directions.Status.LastQueryTime,
+			TravelTimes:   directions.Status.TravelTimes,
+			Conditions:    directions.Status.Conditions,
 		}
-		directions.Status.Distance = route[0].Legs[x].Distance.HumanReadable
-		directions.Status.Duration = fmt.Sprintf("Total Minutes: %f", route[0].Legs[x].Duration.Minutes())
-		directions.Status.StartLocation = route[0].Legs[x].StartAddress
-		directions.Status.EndLocation = route[0].Legs[x].EndAddress
+		setCondition(&directions.Status.Conditions, directions.Generation, katnavv1.ConditionProviderError, false, "Succeeded", "")
+		withinQuota(&directions.Status.Conditions, directions.Generation)
+		directions.Status.Error = "no route could be found between the source and destination"
+		setCondition(&directions.Status.Conditions, directions.Generation, katnavv1.ConditionRouteFound, false, provider.ReasonZeroResults, directions.Status.Error)
+		setCondition(&directions.Status.Conditions, directions.Generation, katnavv1.ConditionReady, false, provider.ReasonZeroResults, directions.Status.Error)
+		return ctrl.Result{}, r.updateStatus(ctx, &directions)
+	}
+
+	return r.routeFound(ctx, &directions, route)
+}
+
+// routeFound updates the status with the routes that have been found
+func (r *DirectionsReconciler) routeFound(ctx context.Context, directions *katnavv1.Directions, route []katnavv1.Route) (ctrl.Result, error) {
+	log.FromContext(ctx).Info("New Route", "Summary", route[0].Summary, "Routes", len(route), "Cached", directions.Status.FromCache)
 
+	var events []notify.Event
+	if directions.Status.RouteSummary != "" && directions.Status.RouteSummary != route[0].Summary {
+		event := newEvent("Directions", directions, notify.ReasonRouteChanged, fmt.Sprintf("route changed from %s to %s", directions.Status.RouteSummary, route[0].Summary))
+		event.PreviousSummary = directions.Status.RouteSummary
+		events = append(events, event)
+	}
+	duration, expected := journeyDuration(route[0])
+	if notifications := directions.Spec.Notifications; notifications != nil && notifications.DurationThreshold != nil {
+		wasDelayed := meta.IsStatusConditionTrue(directions.Status.Conditions, katnavv1.ConditionDelayExceeded)
+		if threshold := notifications.DurationThreshold.Duration; expected > threshold {
+			message := fmt.Sprintf("journey is expected to take %s, which is %s longer than %s", duration, expected-threshold, threshold)
+			setCondition(&directions.Status.Conditions, directions.Generation, katnavv1.ConditionDelayExceeded, true, "DurationThresholdExceeded", message)
+			if !wasDelayed {
+				events = append(events, newEvent("Directions", directions, notify.ReasonDelayExceeded, message))
+			}
+		} else {
+			setCondition(&directions.Status.Conditions, directions.Generation, katnavv1.ConditionDelayExceeded, false, "WithinDurationThreshold", "")
+			if wasDelayed {
+				events = append(events, newEvent("Directions", directions, notify.ReasonDelayCleared, fmt.Sprintf("journey is expected to take %s", duration)))
+			}
+		}
+	} else {
+		meta.RemoveStatusCondition(&directions.Status.Conditions, katnavv1.ConditionDelayExceeded)
 	}
 
+	// A route served from the cache is still a change to the history when it
+	// differs from the routes that were in the status
+	routesChanged := !reflect.DeepEqual(directions.Status.Routes, route)
+	directions.Status.Routes = route
 	directions.Status.RouteSummary = route[0].Summary
-	directions.Status.Directions = directionsString
+	directions.Status.StartLocation = route[0].StartLocation
+	directions.Status.EndLocation = route[0].EndLocation
+	directions.Status.Distance = route[0].Distance
+	directions.Status.DistanceMeters = route[0].DistanceMeters
+	directions.Status.Duration = route[0].Duration
+	directions.Status.DurationSeconds = route[0].DurationSeconds
+	directions.Status.DurationInTraffic = route[0].DurationInTraffic
+	directions.Status.DurationInTrafficSeconds = route[0].DurationInTrafficSeconds
+	directions.Status.Fare = route[0].Fare
+	if directions.Status.LastQueryTime != nil {
+		directions.Status.TravelTimes = recordTravelTime(directions.Status.TravelTimes, katnavv1.TravelTime{
+			Time:                     *directions.Status.LastQueryTime,
+			DurationSeconds:          route[0].DurationSeconds,
+			DurationInTrafficSeconds: route[0].DurationInTrafficSeconds,
+		})
+	}
+	directions.Status.Directions = directionsText(route[0])
+	directions.Status.Error = ""
+	setCondition(&directions.Status.Conditions, directions.Generation, katnavv1.ConditionProviderError, false, "Succeeded", "")
+	withinQuota(&directions.Status.Conditions, directions.Generation)
+	setCondition(&directions.Status.Conditions, directions.Generation, katnavv1.ConditionRouteFound, true, "RoutesReturned", fmt.Sprintf("%d route(s) found", len(route)))
+	setCondition(&directions.Status.Conditions, directions.Generation, katnavv1.ConditionReady, true, "RouteFound", "")
 
-	err = r.Client.Status().Update(context.TODO(), &directions, &client.UpdateOptions{})
-	if err != nil {
-		log.Error(err, "unable to update journey")
+	directions.Status.ExportConfigMap = exportRoutes(ctx, r.Client, r.Scheme, directions, directions.Spec.Exports, route, &directions.Status.Conditions)
+	if (!directions.Status.FromCache || routesChanged) && directions.Status.LastQueryTime != nil {
+		r.recordSnapshot(ctx, directions, route[0], *directions.Status.LastQueryTime)
 	}
-	return ctrl.Result{}, nil
+	retry := r.enrichRoute(ctx, directions, route[0])
+
+	if err := r.updateStatus(ctx, directions); err != nil {
+		return ctrl.Result{}, err
+	}
+	for x := range events {
+		events[x].Summary = route[0].Summary
+		events[x].Duration = duration
+		events[x].DurationSeconds = int64(expected.Seconds())
+		r.Notifier.Notify(ctx, directions.Namespace, directions.Spec.Notifications, events[x])
+	}
+	return ctrl.Result{RequeueAfter: soonest(refreshAfter(directions.Spec.RefreshInterval, directions.Status.LastQueryTime), retry)}, nil
 }
 
-// SetupWithManager sets up the controller with the Manager.
-func (r *DirectionsReconciler) SetupWithManager(mgr ctrl.Manager) error {
+// providerError records why a route couldn't be found, transient errors are
+// returned so that the request is retried with a backoff
+func (r *DirectionsReconciler) providerError(ctx context.Context, directions *katnavv1.Directions, err error) (ctrl.Result, error) {
+	reason, transient := provider.ReasonFor(err)
+	log.FromContext(ctx).Error(err, "unable to fetch Directions", "Reason", reason, "Transient", transient)
 
-	// So we have a Kubernetes cluster in r.Client, however we can't use it until the caches
-	// start otherwise it will just return an error. So in order to get things ready we will
-	// use our own client in order to get the key and set up the Google Maps client in advance
-	config, err := restClient.InClusterConfig()
-	if err != nil {
-		kubeConfig :=
-			cmdClient.NewDefaultClientConfigLoadingRules().GetDefaultFilename()
-		config, err = cmdClient.BuildConfigFromFlags("", kubeConfig)
-		if err != nil {
-			return err
+	directions.Status.Error = err.Error()
+	setCondition(&directions.Status.Conditions, directions.Generation, katnavv1.ConditionReady, false, reason, err.Error())
+
+	if reset, exhausted := quotaExhausted(&directions.Status.Conditions, directions.Generation, err); exhausted {
+		if updateErr := r.updateStatus(ctx, directions); updateErr != nil {
+			return ctrl.Result{}, updateErr
 		}
+		return ctrl.Result{RequeueAfter: reset}, nil
 	}
-	// create the clientset
-	clientset, err := kubernetes.NewForConfig(config)
-	if err != nil {
-		panic(err.Error())
+
+	setCondition(&directions.Status.Conditions, directions.Generation, katnavv1.ConditionProviderError, true, reason, err.Error())
+	if reason == provider.ReasonZeroResults || reason == provider.ReasonNotFound {
+		setCondition(&directions.Status.Conditions, directions.Generation, katnavv1.ConditionRouteFound, false, reason, err.Error())
 	}
+	if updateErr := r.updateStatus(ctx, directions); updateErr != nil {
+		return ctrl.Result{}, updateErr
+	}
+	if transient {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
 
-	secret, err := clientset.CoreV1().Secrets("default").Get(context.TODO(), "katnav", v1.GetOptions{})
+// updateStatus writes the status of the Directions for the generation it describes
+func (r *DirectionsReconciler) updateStatus(ctx context.Context, directions *katnavv1.Directions) error {
+	directions.Status.ObservedGeneration = directions.Generation
+	err := r.Client.Status().Update(ctx, directions, &client.UpdateOptions{})
 	if err != nil {
-		return err
+		log.FromContext(ctx).Error(err, "unable to update journey")
+	}
+	return err
+}
+
+// directionsText joins all of the instructions for a route, one per line
+func directionsText(route katnavv1.Route) string {
+	var directionsString string
+	for x := range route.Legs {
+		for y := range route.Legs[x].Steps {
+			directionsString += route.Legs[x].Steps[y].Instruction + "\n"
+			if transit := route.Legs[x].Steps[y].Transit; transit != nil {
+				directionsString += "  " + transitText(transit) + "\n"
+			}
+		}
 	}
+	return directionsString
+}
 
-	token := string(secret.Data["directionsKey"])
-	if token == "" {
-		return fmt.Errorf("no Token found within API Key")
+// transitText describes the line, stops and times of a transit step, e.g.
+// "Jubilee (SUBWAY) from Bond Street at 08:15 UTC to Canary Wharf at 08:32 UTC, 6 stops"
+func transitText(transit *katnavv1.TransitDetails) string {
+	line := transit.LineShortName
+	if line == "" {
+		line = transit.LineName
 	}
-	r.mClient, err = maps.NewClient(maps.WithAPIKey(token))
-	if err != nil {
-		return err
+	if transit.VehicleType != "" {
+		line += " (" + transit.VehicleType + ")"
+	}
+	text := fmt.Sprintf("%s from %s", line, transit.DepartureStop.Name)
+	if transit.DepartureTime != nil {
+		text += " at " + transit.DepartureTime.Format("15:04 MST")
+	}
+	text += " to " + transit.ArrivalStop.Name
+	if transit.ArrivalTime != nil {
+		text += " at " + transit.ArrivalTime.Format("15:04 MST")
+	}
+	if transit.NumStops != 0 {
+		text += fmt.Sprintf(", %d stops", transit.NumStops)
 	}
+	return text
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *DirectionsReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&katnavv1.Directions{}).
+		For(&katnavv1.Directions{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(r.secretToDirections)).
+		Watches(&source.Kind{Type: &katnavv1.Location{}}, handler.EnqueueRequestsFromMapFunc(r.locationToDirections)).
 		Complete(r)
 }
+
+// locationToDirections finds every Directions object that references a
+// Location, so that they are routed again when it changes
+func (r *DirectionsReconciler) locationToDirections(obj client.Object) []reconcile.Request {
+	var directionsList katnavv1.DirectionsList
+	if err := r.List(context.TODO(), &directionsList, client.InNamespace(obj.GetNamespace())); err != nil {
+		return nil
+	}
+	var requests []reconcile.Request
+	for x := range directionsList.Items {
+		directions := &directionsList.Items[x]
+		if usesLocation(&directions.Spec, obj.GetName()) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: directions.Namespace, Name: directions.Name},
+			})
+		}
+	}
+	return requests
+}
+
+// secretToDirections finds every Directions object that uses the API key in a
+// Secret, so that they are reconciled with the new key when it is rotated
+func (r *DirectionsReconciler) secretToDirections(obj client.Object) []reconcile.Request {
+	var directionsList katnavv1.DirectionsList
+	if err := r.List(context.TODO(), &directionsList); err != nil {
+		return nil
+	}
+	secret := types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+	var requests []reconcile.Request
+	for x := range directionsList.Items {
+		directions := &directionsList.Items[x]
+		if r.Providers.UsesSecret(directions.Spec.Provider, directions.Namespace, directions.Spec.SecretRef, secret) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: directions.Namespace, Name: directions.Name},
+			})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/provider"
+)
+
+// DistanceMatrixReconciler reconciles a DistanceMatrix object
+type DistanceMatrixReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// Providers are the same routing backends that Directions use
+	Providers *Providers
+}
+
+//+kubebuilder:rbac:groups=katnav.fnnrn.me,resources=distancematrices,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=katnav.fnnrn.me,resources=distancematrices/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=katnav.fnnrn.me,resources=distancematrices/finalizers,verbs=update
+
+// Reconcile builds a matrix for each mode of a DistanceMatrix, like Directions
+// the provider is only queried when the spec changes or a refresh is due
+func (r *DistanceMatrixReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	var distanceMatrix katnavv1.DistanceMatrix
+	if err := r.Get(ctx, req.NamespacedName, &distanceMatrix); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "unable to fetch DistanceMatrix object")
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	hash, err := specHash(distanceMatrix.Spec)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if hash == distanceMatrix.Status.SpecHash && meta.IsStatusConditionTrue(distanceMatrix.Status.Conditions, katnavv1.ConditionReady) {
+		refresh := refreshAfter(distanceMatrix.Spec.RefreshInterval, distanceMatrix.Status.LastQueryTime)
+		if distanceMatrix.Spec.RefreshInterval == nil || refresh > 0 {
+			return ctrl.Result{RequeueAfter: refresh}, nil
+		}
+	}
+	distanceMatrix.Status.SpecHash = hash
+	now := metav1.Now()
+	distanceMatrix.Status.LastQueryTime = &now
+
+	matrixProvider, err := r.Providers.Matrix(ctx, matrixProviderName(&distanceMatrix), distanceMatrix.Namespace, distanceMatrix.Spec.SecretRef)
+	if err != nil {
+		return r.matrixError(ctx, &distanceMatrix, err)
+	}
+
+	modes := distanceMatrix.Spec.Modes
+	if len(modes) == 0 {
+		modes = []katnavv1.TravelMode{katnavv1.TravelModeDriving}
+	}
+	matrices := make([]katnavv1.Matrix, 0, len(modes))
+	for _, mode := range modes {
+		log.Info("Building distance matrix", "Mode", mode, "Origins", len(distanceMatrix.Spec.Origins), "Destinations", len(distanceMatrix.Spec.Destinations))
+		rows, err := matrixProvider.DistanceMatrix(ctx, provider.NewMatrixRequest(&distanceMatrix.Spec, mode))
+		if err != nil {
+			return r.matrixError(ctx, &distanceMatrix, err)
+		}
+		matrices = append(matrices, katnavv1.Matrix{Mode: mode, Rows: rows})
+	}
+
+	found, total := 0, 0
+	for x := range matrices {
+		for y := range matrices[x].Rows {
+			for z := range matrices[x].Rows[y].Elements {
+				total++
+				if matrices[x].Rows[y].Elements[z].ErrorCode == "" {
+					found++
+				}
+			}
+		}
+	}
+	distanceMatrix.Status.Matrices = matrices
+	distanceMatrix.Status.Error = ""
+	withinQuota(&distanceMatrix.Status.Conditions, distanceMatrix.Generation)
+	setCondition(&distanceMatrix.Status.Conditions, distanceMatrix.Generation, katnavv1.ConditionReady, true, "MatrixBuilt", fmt.Sprintf("%d of %d journeys found", found, total))
+
+	if err = r.updateStatus(ctx, &distanceMatrix); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: refreshAfter(distanceMatrix.Spec.RefreshInterval, distanceMatrix.Status.LastQueryTime)}, nil
+}
+
+// matrixProviderName returns the provider of a DistanceMatrix, only Google is
+// able to build a matrix so it is used unless another provider has been asked for
+func matrixProviderName(distanceMatrix *katnavv1.DistanceMatrix) string {
+	if distanceMatrix.Spec.Provider == "" {
+		return provider.Google
+	}
+	return distanceMatrix.Spec.Provider
+}
+
+// matrixError records why a matrix couldn't be built, transient errors are
+// returned so that the request is retried with a backoff
+func (r *DistanceMatrixReconciler) matrixError(ctx context.Context, distanceMatrix *katnavv1.DistanceMatrix, err error) (ctrl.Result, error) {
+	reason, transient := provider.ReasonFor(err)
+	log.FromContext(ctx).Error(err, "unable to build DistanceMatrix", "Reason", reason, "Transient", transient)
+
+	distanceMatrix.Status.Error = err.Error()
+	setCondition(&distanceMatrix.Status.Conditions, distanceMatrix.Generation, katnavv1.ConditionReady, false, reason, err.Error())
+
+	if reset, exhausted := quotaExhausted(&distanceMatrix.Status.Conditions, distanceMatrix.Generation, err); exhausted {
+		if updateErr := r.updateStatus(ctx, distanceMatrix); updateErr != nil {
+			return ctrl.Result{}, updateErr
+		}
+		return ctrl.Result{RequeueAfter: reset}, nil
+	}
+	if updateErr := r.updateStatus(ctx, distanceMatrix); updateErr != nil {
+		return ctrl.Result{}, updateErr
+	}
+	if transient {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// updateStatus writes the status of the DistanceMatrix for the generation it describes
+func (r *DistanceMatrixReconciler) updateStatus(ctx context.Context, distanceMatrix *katnavv1.DistanceMatrix) error {
+	distanceMatrix.Status.ObservedGeneration = distanceMatrix.Generation
+	err := r.Client.Status().Update(ctx, distanceMatrix, &client.UpdateOptions{})
+	if err != nil {
+		log.FromContext(ctx).Error(err, "unable to update distance matrix")
+	}
+	return err
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *DistanceMatrixReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&katnavv1.DistanceMatrix{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(r.secretToDistanceMatrices)).
+		Complete(r)
+}
+
+// secretToDistanceMatrices finds every DistanceMatrix that uses the API key in
+// a Secret, so that they are built again once it is created or rotated
+func (r *DistanceMatrixReconciler) secretToDistanceMatrices(obj client.Object) []reconcile.Request {
+	var distanceMatrices katnavv1.DistanceMatrixList
+	if err := r.List(context.TODO(), &distanceMatrices); err != nil {
+		return nil
+	}
+	secret := types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+	var requests []reconcile.Request
+	for x := range distanceMatrices.Items {
+		distanceMatrix := &distanceMatrices.Items[x]
+		if r.Providers.UsesSecret(matrixProviderName(distanceMatrix), distanceMatrix.Namespace, distanceMatrix.Spec.SecretRef, secret) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: distanceMatrix.Namespace, Name: distanceMatrix.Name},
+			})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/elevation"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/export"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/provider"
+)
+
+// defaultElevationResolution is used when the resolution isn't set
+const defaultElevationResolution = 100
+
+// elevationName is the name of the ConfigMap that the elevation profile of an
+// object is written to
+func elevationName(owner metav1.Object) string {
+	return owner.GetName() + "-elevation"
+}
+
+// elevationDue returns true if the elevation is wanted but the last attempt to
+// find it failed
+func elevationDue(directions *katnavv1.Directions) bool {
+	return directions.Spec.Elevation != nil && !meta.IsStatusConditionTrue(directions.Status.Conditions, katnavv1.ConditionElevationFound)
+}
+
+// profileElevation samples the elevation along a route and writes the profile
+// to a ConfigMap owned by the Directions, the provider is only asked again when
+// the sampled path changes. It returns how long to wait before a failure is
+// worth trying again, zero means that it isn't.
+func (r *DirectionsReconciler) profileElevation(ctx context.Context, directions *katnavv1.Directions, route katnavv1.Route) time.Duration {
+	conditions := &directions.Status.Conditions
+	failed := func(reason string, err error) {
+		log.FromContext(ctx).Error(err, "unable to profile elevation", "Reason", reason)
+		setCondition(conditions, directions.Generation, katnavv1.ConditionElevationFound, false, reason, err.Error())
+	}
+
+	if directions.Spec.Elevation == nil {
+		if err := writeOwnedConfigMap(ctx, r.Client, r.Scheme, directions, elevationName(directions), nil); err != nil {
+			failed("ConfigMapFailed", err)
+			return 0
+		}
+		directions.Status.Elevation = nil
+		directions.Status.ElevationConfigMap = ""
+		meta.RemoveStatusCondition(conditions, katnavv1.ConditionElevationFound)
+		return 0
+	}
+
+	resolution := directions.Spec.Elevation.ResolutionMeters
+	if resolution <= 0 {
+		resolution = defaultElevationResolution
+	}
+	path, err := export.RoutePoints(route)
+	if err != nil {
+		failed("InvalidPolyline", err)
+		return 0
+	}
+	if len(path) == 0 {
+		failed("NoPath", fmt.Errorf("the route has no path to sample"))
+		return 0
+	}
+	profile := elevation.Sample(path, float64(resolution))
+	hash, err := specHash(profile.Locations())
+	if err != nil {
+		failed("InvalidPath", err)
+		return 0
+	}
+	if directions.Status.Elevation != nil && directions.Status.Elevation.PathHash == hash && !elevationDue(directions) {
+		return 0
+	}
+
+	elevationProvider, err := r.Providers.Elevation(ctx, directions.Namespace, directions.Spec.SecretRef)
+	if err == nil {
+		var elevations []float64
+		if elevations, err = elevationProvider.Elevation(ctx, profile.Locations()); err == nil {
+			err = profile.SetElevations(elevations)
+		}
+	}
+	if err != nil {
+		reason, _ := provider.ReasonFor(err)
+		failed(reason, err)
+		return providerRetry(err)
+	}
+
+	b, err := profile.JSON()
+	if err == nil {
+		err = writeOwnedConfigMap(ctx, r.Client, r.Scheme, directions, elevationName(directions), map[string]string{elevation.Key: string(b)})
+	}
+	if err != nil {
+		failed("ConfigMapFailed", err)
+		return enrichRetry
+	}
+
+	summary := profile.Summary()
+	summary.PathHash = hash
+	directions.Status.Elevation = &summary
+	directions.Status.ElevationConfigMap = elevationName(directions)
+	setCondition(conditions, directions.Generation, katnavv1.ConditionElevationFound, true, "ElevationFound",
+		fmt.Sprintf("%d samples every %vm", summary.Samples, summary.ResolutionMeters))
+	return 0
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	goerrors "errors"
+	"time"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/provider"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/quota"
+)
+
+// enrichRetry is how long to wait before a transient failure to find the
+// details of a route is tried again
+const enrichRetry = time.Minute
+
+// enrichRoute adds the local departure and arrival times, the elevation and the
+// places along the route to the status, these are found separately to the
+// route so that they can fail on their own. It returns how long to wait before
+// a failure is worth trying again, zero means that it isn't.
+func (r *DirectionsReconciler) enrichRoute(ctx context.Context, directions *katnavv1.Directions, route katnavv1.Route) time.Duration {
+	retry := r.resolveJourneyTimes(ctx, directions, route)
+	retry = soonest(retry, r.profileElevation(ctx, directions, route))
+	return soonest(retry, r.findPlaces(ctx, directions, route))
+}
+
+// enrichmentDue returns true if the details of a route are wanted but the last
+// attempt to find them failed
+func enrichmentDue(directions *katnavv1.Directions) bool {
+	return timesDue(directions) || elevationDue(directions) || placesDue(directions)
+}
+
+// providerRetry returns how long to wait before a request that failed is worth
+// trying again, zero means that it isn't
+func providerRetry(err error) time.Duration {
+	var exhausted *quota.ExhaustedError
+	if goerrors.As(err, &exhausted) {
+		return time.Until(exhausted.ResetTime)
+	}
+	if _, transient := provider.ReasonFor(err); transient {
+		return enrichRetry
+	}
+	return 0
+}
+
+// soonest returns the shortest of two durations, ignoring either one if it is
+// zero
+func soonest(a, b time.Duration) time.Duration {
+	if a == 0 || (b > 0 && b < a) {
+		return b
+	}
+	return a
+}
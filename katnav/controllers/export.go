@@ -0,0 +1,115 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/export"
+)
+
+// exportName is the name of the ConfigMap that the routes of an object are
+// exported to
+func exportName(owner metav1.Object) string {
+	return owner.GetName() + "-export"
+}
+
+// writeExports writes the routes in each format to a ConfigMap owned by the
+// object, the ConfigMap is removed when there are no formats. It returns the
+// name of the ConfigMap when there is one.
+func writeExports(ctx context.Context, c client.Client, scheme *runtime.Scheme, owner client.Object, formats []katnavv1.ExportFormat, routes []katnavv1.Route) (string, error) {
+	if len(formats) == 0 {
+		return "", writeOwnedConfigMap(ctx, c, scheme, owner, exportName(owner), nil)
+	}
+
+	routeExport, err := export.FromRoutes(owner.GetName(), routes)
+	if err != nil {
+		return "", err
+	}
+	data := map[string]string{}
+	for _, format := range formats {
+		b, err := routeExport.Encode(format)
+		if err != nil {
+			return "", err
+		}
+		data[export.Key(format)] = string(b)
+	}
+	return exportName(owner), writeOwnedConfigMap(ctx, c, scheme, owner, exportName(owner), data)
+}
+
+// writeOwnedConfigMap creates or updates a ConfigMap that is owned by the
+// object, the ConfigMap is removed when there is no data
+func writeOwnedConfigMap(ctx context.Context, c client.Client, scheme *runtime.Scheme, owner client.Object, name string, data map[string]string) error {
+	configMap := &corev1.ConfigMap{}
+	key := types.NamespacedName{Namespace: owner.GetNamespace(), Name: name}
+	err := c.Get(ctx, key, configMap)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+	// Never overwrite a ConfigMap that somebody else created
+	if exists && !metav1.IsControlledBy(configMap, owner) {
+		return fmt.Errorf("ConfigMap %s already exists and isn't owned by %s", key.Name, owner.GetName())
+	}
+
+	if len(data) == 0 {
+		if exists {
+			return client.IgnoreNotFound(c.Delete(ctx, configMap))
+		}
+		return nil
+	}
+
+	configMap.Data = data
+	if exists {
+		return c.Update(ctx, configMap)
+	}
+	configMap.Name = key.Name
+	configMap.Namespace = key.Namespace
+	if err = controllerutil.SetControllerReference(owner, configMap, scheme); err != nil {
+		return err
+	}
+	return c.Create(ctx, configMap)
+}
+
+// exportRoutes writes the exports of an object and records the result in the
+// Exported condition, a failure doesn't stop the routes from being reported
+func exportRoutes(ctx context.Context, c client.Client, scheme *runtime.Scheme, owner client.Object, formats []katnavv1.ExportFormat, routes []katnavv1.Route, conditions *[]metav1.Condition) string {
+	name, err := writeExports(ctx, c, scheme, owner, formats, routes)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "unable to export routes")
+		setCondition(conditions, owner.GetGeneration(), katnavv1.ConditionExported, false, "ExportFailed", err.Error())
+		return ""
+	}
+	if len(formats) == 0 {
+		meta.RemoveStatusCondition(conditions, katnavv1.ConditionExported)
+	} else {
+		setCondition(conditions, owner.GetGeneration(), katnavv1.ConditionExported, true, "Exported", "")
+	}
+	return name
+}
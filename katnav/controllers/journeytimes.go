@@ -0,0 +1,126 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/provider"
+)
+
+const (
+	// reasonUnknownTimeZone is used when a time zone isn't in the time zone
+	// database of the controller
+	reasonUnknownTimeZone = "UnknownTimeZone"
+	// reasonTimeZonesUnavailable is used when the provider of a journey isn't
+	// Google, which is the only one able to look up time zones
+	reasonTimeZonesUnavailable = "TimeZonesUnavailable"
+)
+
+// departureTime returns when a journey leaves, which is the departure time of
+// the spec, the arrival time less the duration of the journey, or when the
+// route was found
+func departureTime(spec *katnavv1.DirectionsSpec, route katnavv1.Route, found time.Time) time.Time {
+	if spec.ArrivalTime != nil {
+		_, expected := journeyDuration(route)
+		return spec.ArrivalTime.Add(-expected)
+	}
+	if departure, err := time.Parse(time.RFC3339, spec.DepartureTime); err == nil {
+		return departure
+	}
+	return found
+}
+
+// journeyTimes works out when a journey departs and arrives, the local times
+// are in the time zones of the start and end of the route. The times in UTC
+// are always returned, along with an error when a time zone isn't known. Only
+// journeys routed by Google look up time zones, so that a journey using
+// another provider never needs a Google API key.
+func journeyTimes(ctx context.Context, providers *Providers, namespace string, spec *katnavv1.DirectionsSpec, route katnavv1.Route, departure time.Time) (*katnavv1.JourneyTime, *katnavv1.JourneyTime, error) {
+	_, expected := journeyDuration(route)
+	start := &katnavv1.JourneyTime{Time: metav1.NewTime(departure.UTC())}
+	end := &katnavv1.JourneyTime{Time: metav1.NewTime(departure.Add(expected).UTC())}
+	if len(route.Legs) == 0 {
+		return start, end, nil
+	}
+	if name := providers.Name(spec.Provider); name != provider.Google {
+		return start, end, &provider.Error{Reason: reasonTimeZonesUnavailable,
+			Err: fmt.Errorf("time zones are only looked up for the %s provider, not %s, so the times are in UTC", provider.Google, name)}
+	}
+	if err := localTime(ctx, providers, namespace, spec.SecretRef, route.Legs[0].StartCoordinates, start); err != nil {
+		return start, end, err
+	}
+	err := localTime(ctx, providers, namespace, spec.SecretRef, route.Legs[len(route.Legs)-1].EndCoordinates, end)
+	return start, end, err
+}
+
+// localTime sets the local time of a journey time from the time zone of the
+// coordinates
+func localTime(ctx context.Context, providers *Providers, namespace string, secretRef *corev1.SecretKeySelector, coordinates katnavv1.LatLng, t *katnavv1.JourneyTime) error {
+	if coordinates == (katnavv1.LatLng{}) {
+		// The provider didn't return the coordinates of the leg
+		return nil
+	}
+	zone, err := providers.TimeZone(ctx, namespace, secretRef, coordinates)
+	if err != nil {
+		return err
+	}
+	location, err := time.LoadLocation(zone)
+	if err != nil {
+		return &provider.Error{Reason: reasonUnknownTimeZone, Err: fmt.Errorf("time zone %q: %w", zone, err)}
+	}
+	local := t.Time.In(location)
+	t.Local = local.Format(time.RFC3339)
+	t.TimeZone = zone
+	t.Abbreviation, _ = local.Zone()
+	return nil
+}
+
+// timesDue returns true if the last attempt to find the local departure and
+// arrival times failed, a provider that can't look up time zones won't be able
+// to until the spec changes
+func timesDue(directions *katnavv1.Directions) bool {
+	resolved := meta.FindStatusCondition(directions.Status.Conditions, katnavv1.ConditionTimeZonesResolved)
+	return resolved == nil || (resolved.Status != metav1.ConditionTrue && resolved.Reason != reasonTimeZonesUnavailable)
+}
+
+// resolveJourneyTimes sets the departure and arrival times of a route. It
+// returns how long to wait before a failure is worth trying again, zero means
+// that it isn't.
+func (r *DirectionsReconciler) resolveJourneyTimes(ctx context.Context, directions *katnavv1.Directions, route katnavv1.Route) time.Duration {
+	found := time.Now()
+	if directions.Status.LastQueryTime != nil {
+		found = directions.Status.LastQueryTime.Time
+	}
+	departure, arrival, err := journeyTimes(ctx, r.Providers, directions.Namespace, &directions.Spec, route, departureTime(&directions.Spec, route, found))
+	directions.Status.Departure = departure
+	directions.Status.Arrival = arrival
+	if err != nil {
+		reason, _ := provider.ReasonFor(err)
+		setCondition(&directions.Status.Conditions, directions.Generation, katnavv1.ConditionTimeZonesResolved, false, reason, err.Error())
+		return providerRetry(err)
+	}
+	setCondition(&directions.Status.Conditions, directions.Generation, katnavv1.ConditionTimeZonesResolved, true, "TimeZonesResolved", "")
+	return 0
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+)
+
+func TestTimesDue(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		condition *metav1.Condition
+		due       bool
+	}{
+		{name: "never resolved", due: true},
+		{name: "resolved", condition: &metav1.Condition{Status: metav1.ConditionTrue, Reason: "TimeZonesResolved"}},
+		{name: "failed", condition: &metav1.Condition{Status: metav1.ConditionFalse, Reason: "MissingAPIKey"}, due: true},
+		{name: "provider without time zones", condition: &metav1.Condition{Status: metav1.ConditionFalse, Reason: reasonTimeZonesUnavailable}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			directions := &katnavv1.Directions{}
+			if test.condition != nil {
+				condition := *test.condition
+				condition.Type = katnavv1.ConditionTimeZonesResolved
+				directions.Status.Conditions = []metav1.Condition{condition}
+			}
+			if due := timesDue(directions); due != test.due {
+				t.Errorf("expected due to be %v", test.due)
+			}
+		})
+	}
+}
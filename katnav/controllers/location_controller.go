@@ -0,0 +1,175 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/provider"
+)
+
+// LocationReconciler reconciles a Location object
+type LocationReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// Providers are used to find the geocoder
+	Providers *Providers
+}
+
+//+kubebuilder:rbac:groups=katnav.fnnrn.me,resources=locations,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=katnav.fnnrn.me,resources=locations/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=katnav.fnnrn.me,resources=locations/finalizers,verbs=update
+
+// Reconcile geocodes the address or coordinates of a Location, it is only
+// resolved again when the spec changes
+func (r *LocationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	var location katnavv1.Location
+	if err := r.Get(ctx, req.NamespacedName, &location); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "unable to fetch Location object")
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	hash, err := specHash(location.Spec)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if hash == location.Status.SpecHash && meta.IsStatusConditionTrue(location.Status.Conditions, katnavv1.ConditionReady) {
+		return ctrl.Result{}, nil
+	}
+	location.Status.SpecHash = hash
+
+	if (location.Spec.Address == "") == (location.Spec.Coordinates == nil) {
+		location.Status.Error = "exactly one of address or coordinates needs to be set"
+		setCondition(&location.Status.Conditions, location.Generation, katnavv1.ConditionReady, false, "InvalidSpec", location.Status.Error)
+		return ctrl.Result{}, r.updateStatus(ctx, &location)
+	}
+
+	geocoder, err := r.Providers.Geocoder(ctx, location.Namespace, location.Spec.SecretRef)
+	if err != nil {
+		return r.resolveError(ctx, &location, err)
+	}
+	var place provider.Place
+	if location.Spec.Address != "" {
+		log.Info("Geocoding location", "Address", location.Spec.Address)
+		place, err = geocoder.Geocode(ctx, location.Spec.Address)
+	} else {
+		log.Info("Reverse geocoding location", "Coordinates", location.Spec.Coordinates)
+		place, err = geocoder.ReverseGeocode(ctx, *location.Spec.Coordinates)
+		// Keep the coordinates that were asked for rather than the address
+		place.Coordinates = *location.Spec.Coordinates
+	}
+	if err != nil {
+		return r.resolveError(ctx, &location, err)
+	}
+	timeZone, err := geocoder.TimeZone(ctx, place.Coordinates, time.Now())
+	if err != nil {
+		return r.resolveError(ctx, &location, err)
+	}
+
+	now := metav1.Now()
+	location.Status.FormattedAddress = place.FormattedAddress
+	location.Status.Coordinates = &place.Coordinates
+	location.Status.PlaceID = place.PlaceID
+	location.Status.TimeZone = timeZone
+	location.Status.LastResolveTime = &now
+	location.Status.Error = ""
+	withinQuota(&location.Status.Conditions, location.Generation)
+	setCondition(&location.Status.Conditions, location.Generation, katnavv1.ConditionReady, true, "Resolved", "")
+	log.Info("Resolved location", "Address", place.FormattedAddress, "TimeZone", timeZone)
+	return ctrl.Result{}, r.updateStatus(ctx, &location)
+}
+
+// resolveError records why a location couldn't be resolved, transient errors
+// are returned so that it is retried with a backoff
+func (r *LocationReconciler) resolveError(ctx context.Context, location *katnavv1.Location, err error) (ctrl.Result, error) {
+	reason, transient := provider.ReasonFor(err)
+	log.FromContext(ctx).Error(err, "unable to resolve Location", "Reason", reason, "Transient", transient)
+
+	location.Status.Error = err.Error()
+	setCondition(&location.Status.Conditions, location.Generation, katnavv1.ConditionReady, false, reason, err.Error())
+	reset, exhausted := quotaExhausted(&location.Status.Conditions, location.Generation, err)
+	if updateErr := r.updateStatus(ctx, location); updateErr != nil {
+		return ctrl.Result{}, updateErr
+	}
+	if exhausted {
+		return ctrl.Result{RequeueAfter: reset}, nil
+	}
+	if transient {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// updateStatus writes the status of the Location for the generation it describes
+func (r *LocationReconciler) updateStatus(ctx context.Context, location *katnavv1.Location) error {
+	location.Status.ObservedGeneration = location.Generation
+	err := r.Client.Status().Update(ctx, location, &client.UpdateOptions{})
+	if err != nil {
+		log.FromContext(ctx).Error(err, "unable to update location")
+	}
+	return err
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *LocationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&katnavv1.Location{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(r.secretToLocations)).
+		Complete(r)
+}
+
+// secretToLocations finds every Location that uses the API key in a Secret
+func (r *LocationReconciler) secretToLocations(obj client.Object) []reconcile.Request {
+	var locationList katnavv1.LocationList
+	if err := r.List(context.TODO(), &locationList); err != nil {
+		return nil
+	}
+	secret := types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+	var requests []reconcile.Request
+	for x := range locationList.Items {
+		location := &locationList.Items[x]
+		if r.Providers.UsesSecret(provider.Google, location.Namespace, location.Spec.SecretRef, secret) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: location.Namespace, Name: location.Name},
+			})
+		}
+	}
+	return requests
+}
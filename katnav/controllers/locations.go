@@ -0,0 +1,91 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+)
+
+// locationError is returned when the source or destination of a journey can't
+// be resolved, it won't be fixed until the spec or the Location changes
+type locationError struct {
+	reason string
+	err    error
+}
+
+func (e *locationError) Error() string {
+	return e.err.Error()
+}
+
+// resolveLocations returns a copy of a spec with any referenced Locations
+// replaced by their coordinates, a reference takes priority over an address
+func resolveLocations(ctx context.Context, c client.Reader, namespace string, spec katnavv1.DirectionsSpec) (katnavv1.DirectionsSpec, error) {
+	var err error
+	if spec.SourceRef != nil {
+		if spec.Source, err = locationCoordinates(ctx, c, namespace, spec.SourceRef.Name); err != nil {
+			return spec, err
+		}
+	}
+	if spec.DestinationRef != nil {
+		if spec.Destination, err = locationCoordinates(ctx, c, namespace, spec.DestinationRef.Name); err != nil {
+			return spec, err
+		}
+	}
+	if spec.Source == "" {
+		return spec, &locationError{reason: "MissingSource", err: fmt.Errorf("either source or sourceRef needs to be set")}
+	}
+	if spec.Destination == "" {
+		return spec, &locationError{reason: "MissingDestination", err: fmt.Errorf("either destination or destinationRef needs to be set")}
+	}
+	return spec, nil
+}
+
+// locationCoordinates returns the coordinates of a Location as "lat,lng",
+// which every provider understands
+func locationCoordinates(ctx context.Context, c client.Reader, namespace, name string) (string, error) {
+	var location katnavv1.Location
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &location); err != nil {
+		if errors.IsNotFound(err) {
+			return "", &locationError{reason: "LocationNotFound", err: fmt.Errorf("location %q not found", name)}
+		}
+		return "", err
+	}
+	if !meta.IsStatusConditionTrue(location.Status.Conditions, katnavv1.ConditionReady) || location.Status.Coordinates == nil {
+		return "", &locationError{reason: "LocationNotReady", err: fmt.Errorf("location %q has not been resolved", name)}
+	}
+	return strconv.FormatFloat(location.Status.Coordinates.Lat, 'f', -1, 64) + "," +
+		strconv.FormatFloat(location.Status.Coordinates.Lng, 'f', -1, 64), nil
+}
+
+// usesLocation returns true if a journey references the named Location
+func usesLocation(spec *katnavv1.DirectionsSpec, name string) bool {
+	return refersTo(spec.SourceRef, name) || refersTo(spec.DestinationRef, name)
+}
+
+func refersTo(ref *corev1.LocalObjectReference, name string) bool {
+	return ref != nil && ref.Name == name
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/notify"
+)
+
+//+kubebuilder:rbac:groups=katnav.fnnrn.me,resources=notificationsinks,verbs=get;list;watch
+//+kubebuilder:rbac:groups=katnav.fnnrn.me,resources=notificationsinks/status,verbs=get;update;patch
+
+// notifyBackoff and notifyMaxBackoff are how long a failed delivery waits
+// before it is retried, doubling each time
+const (
+	notifyBackoff    = time.Second
+	notifyMaxBackoff = 5 * time.Minute
+)
+
+// Notifier sends events to the NotificationSinks that an object references and
+// records each delivery in the status of the sink. Events are delivered in the
+// background by a queue that is started by the manager.
+type Notifier struct {
+	client.Client
+	Queue *notify.Queue
+}
+
+// NewNotifier returns a notifier that delivers events with a sender
+func NewNotifier(c client.Client, sender *notify.Sender) *Notifier {
+	n := &Notifier{
+		Client: c,
+		Queue:  notify.NewQueue(sender, notifyBackoff, notifyMaxBackoff),
+	}
+	n.Queue.Resolve = n.resolveSink
+	n.Queue.Record = n.recordDelivery
+	return n
+}
+
+// Start delivers events until the manager is stopped
+func (n *Notifier) Start(ctx context.Context) error {
+	return n.Queue.Start(ctx)
+}
+
+// Notify queues an event for every sink, deliveries that fail are recorded on
+// the sink rather than stopping the reconcile
+func (n *Notifier) Notify(ctx context.Context, namespace string, notifications *katnavv1.Notifications, event notify.Event) {
+	if n == nil || notifications == nil {
+		return
+	}
+	for x := range notifications.Sinks {
+		n.Queue.Add(&notify.Delivery{
+			Sink:  types.NamespacedName{Namespace: namespace, Name: notifications.Sinks[x].Name},
+			Event: event,
+		})
+	}
+}
+
+// resolveSink returns where a sink is and how many times to retry it
+func (n *Notifier) resolveSink(ctx context.Context, key types.NamespacedName) (notify.Target, error) {
+	var sink katnavv1.NotificationSink
+	if err := n.Get(ctx, key, &sink); err != nil {
+		return notify.Target{}, err
+	}
+	url, err := n.sinkURL(ctx, &sink)
+	if err != nil {
+		return notify.Target{Retries: sink.Spec.Retries}, err
+	}
+	return notify.Target{Type: sink.Spec.Type, URL: url, Retries: sink.Spec.Retries}, nil
+}
+
+// sinkURL returns the URL of a sink, reading it from a Secret when needed
+func (n *Notifier) sinkURL(ctx context.Context, sink *katnavv1.NotificationSink) (string, error) {
+	if sink.Spec.URLSecretRef == nil {
+		if sink.Spec.URL == "" {
+			return "", fmt.Errorf("sink has no url or urlSecretRef")
+		}
+		return sink.Spec.URL, nil
+	}
+	var secret corev1.Secret
+	if err := n.Get(ctx, types.NamespacedName{Namespace: sink.Namespace, Name: sink.Spec.URLSecretRef.Name}, &secret); err != nil {
+		return "", err
+	}
+	url, ok := secret.Data[sink.Spec.URLSecretRef.Key]
+	if !ok || len(url) == 0 {
+		return "", fmt.Errorf("key %q not found in Secret %s", sink.Spec.URLSecretRef.Key, sink.Spec.URLSecretRef.Name)
+	}
+	return string(url), nil
+}
+
+// recordDelivery updates the status of a sink with the result of a delivery
+func (n *Notifier) recordDelivery(ctx context.Context, delivery *notify.Delivery, attempts int, deliveryErr error) {
+	log := log.FromContext(ctx)
+	key, event := delivery.Sink, delivery.Event
+	if deliveryErr != nil {
+		log.Error(deliveryErr, "unable to deliver notification", "Sink", key.Name, "Reason", event.Reason, "Attempts", attempts)
+	}
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var sink katnavv1.NotificationSink
+		if err := n.Get(ctx, key, &sink); err != nil {
+			return err
+		}
+		now := metav1.Now()
+		sink.Status.LastDeliveryTime = &now
+		sink.Status.LastReason = event.Reason
+		sink.Status.LastSource = fmt.Sprintf("%s/%s", event.Kind, event.Name)
+		sink.Status.LastAttempts = attempts
+		if deliveryErr != nil {
+			sink.Status.LastError = deliveryErr.Error()
+			sink.Status.Failed++
+			setCondition(&sink.Status.Conditions, sink.Generation, katnavv1.ConditionDelivered, false, "DeliveryFailed", deliveryErr.Error())
+		} else {
+			sink.Status.LastError = ""
+			sink.Status.Delivered++
+			setCondition(&sink.Status.Conditions, sink.Generation, katnavv1.ConditionDelivered, true, "Delivered", "")
+		}
+		return n.Status().Update(ctx, &sink, &client.UpdateOptions{})
+	})
+	if err != nil {
+		log.Error(err, "unable to update NotificationSink", "Sink", key.Name)
+	}
+}
+
+// newEvent builds an event about an object
+func newEvent(kind string, obj metav1.Object, reason, message string) notify.Event {
+	return notify.Event{
+		Kind:      kind,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		Reason:    reason,
+		Message:   message,
+		Time:      time.Now().UTC(),
+	}
+}
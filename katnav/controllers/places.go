@@ -0,0 +1,187 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/export"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/geo"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/provider"
+)
+
+const (
+	// defaultPlacesRadius and defaultPlacesResults are used when a search
+	// doesn't set them
+	defaultPlacesRadius  = 500
+	defaultPlacesResults = 10
+	// maxPlacesSearches is the most points along a route that are searched
+	// around for each search, on long routes they are spread further apart
+	maxPlacesSearches = 25
+	// maxPlacesRadius is the largest radius that the provider will search
+	maxPlacesRadius = 50000
+)
+
+// placesDue returns true if places along the route are wanted but the last
+// attempt to find them failed
+func placesDue(directions *katnavv1.Directions) bool {
+	return len(directions.Spec.AlongRoute) != 0 && !meta.IsStatusConditionTrue(directions.Status.Conditions, katnavv1.ConditionPlacesFound)
+}
+
+// findPlaces runs each search along the route and lists the places in the
+// status, the provider is only asked again when the path or the searches
+// change. It returns how long to wait before a failure is worth trying again,
+// zero means that it isn't.
+func (r *DirectionsReconciler) findPlaces(ctx context.Context, directions *katnavv1.Directions, route katnavv1.Route) time.Duration {
+	conditions := &directions.Status.Conditions
+	failed := func(reason string, err error) {
+		log.FromContext(ctx).Error(err, "unable to find places along the route", "Reason", reason)
+		setCondition(conditions, directions.Generation, katnavv1.ConditionPlacesFound, false, reason, err.Error())
+	}
+
+	if len(directions.Spec.AlongRoute) == 0 {
+		directions.Status.AlongRoute = nil
+		directions.Status.AlongRouteHash = ""
+		meta.RemoveStatusCondition(conditions, katnavv1.ConditionPlacesFound)
+		return 0
+	}
+
+	path, err := export.RoutePoints(route)
+	if err != nil {
+		failed("InvalidPolyline", err)
+		return 0
+	}
+	if len(path) == 0 {
+		failed("NoPath", fmt.Errorf("the route has no path to search along"))
+		return 0
+	}
+	hash, err := specHash(struct {
+		Path     []katnavv1.LatLng
+		Searches []katnavv1.AlongRouteSearch
+		Language string
+	}{path, directions.Spec.AlongRoute, directions.Spec.Language})
+	if err != nil {
+		failed("InvalidPath", err)
+		return 0
+	}
+	if hash == directions.Status.AlongRouteHash && !placesDue(directions) {
+		return 0
+	}
+
+	placesProvider, err := r.Providers.Places(ctx, directions.Namespace, directions.Spec.SecretRef)
+	if err != nil {
+		reason, _ := provider.ReasonFor(err)
+		failed(reason, err)
+		return providerRetry(err)
+	}
+	var results []katnavv1.AlongRouteResult
+	var found int
+	for _, search := range directions.Spec.AlongRoute {
+		places, err := searchAlongRoute(ctx, placesProvider, path, search, directions.Spec.Language)
+		if err != nil {
+			reason, _ := provider.ReasonFor(err)
+			failed(reason, fmt.Errorf("search %q: %w", search.Name, err))
+			return providerRetry(err)
+		}
+		found += len(places)
+		results = append(results, katnavv1.AlongRouteResult{Name: search.Name, Places: places})
+	}
+
+	directions.Status.AlongRoute = results
+	directions.Status.AlongRouteHash = hash
+	setCondition(conditions, directions.Generation, katnavv1.ConditionPlacesFound, true, "PlacesFound",
+		fmt.Sprintf("%d place(s) found by %d search(es)", found, len(results)))
+	return 0
+}
+
+// searchAlongRoute searches around points spread along the path and keeps the
+// places that are within the radius of the path. The places with the shortest
+// detour are kept, in the order that they are passed.
+func searchAlongRoute(ctx context.Context, placesProvider provider.PlacesProvider, path []katnavv1.LatLng, search katnavv1.AlongRouteSearch, language string) ([]katnavv1.RoutePlace, error) {
+	radius := float64(search.RadiusMeters)
+	if radius <= 0 {
+		radius = defaultPlacesRadius
+	}
+	maxResults := int(search.MaxResults)
+	if maxResults <= 0 {
+		maxResults = defaultPlacesResults
+	}
+
+	samples, spacing := geo.Interpolate(path, radius, maxPlacesSearches)
+	// When the points are further apart than the radius the search around
+	// each one is widened to cover the gaps, places are still filtered by
+	// their distance from the path
+	searchRadius := math.Min(math.Max(radius, spacing/2), maxPlacesRadius)
+
+	seen := map[string]bool{}
+	var places []katnavv1.RoutePlace
+	for _, sample := range samples {
+		results, err := placesProvider.NearbySearch(ctx, &provider.PlacesRequest{
+			Location:     sample.Location,
+			RadiusMeters: int(searchRadius),
+			Type:         search.Type,
+			Keyword:      search.Keyword,
+			Language:     language,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, result := range results {
+			id := result.PlaceID
+			if id == "" {
+				id = fmt.Sprintf("%s@%v", result.Name, result.Coordinates)
+			}
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			fromPath, along := geo.Nearest(path, result.Coordinates)
+			if fromPath > radius {
+				continue
+			}
+			places = append(places, katnavv1.RoutePlace{
+				Name:                     result.Name,
+				PlaceID:                  result.PlaceID,
+				Address:                  result.FormattedAddress,
+				Location:                 result.Coordinates,
+				Rating:                   result.Rating,
+				DistanceAlongRouteMeters: int(along),
+				DistanceFromRouteMeters:  int(fromPath),
+				DetourMeters:             int(2 * fromPath),
+			})
+		}
+	}
+
+	sort.SliceStable(places, func(i, j int) bool {
+		return places[i].DetourMeters < places[j].DetourMeters
+	})
+	if len(places) > maxResults {
+		places = places[:maxResults]
+	}
+	sort.SliceStable(places, func(i, j int) bool {
+		return places[i].DistanceAlongRouteMeters < places[j].DistanceAlongRouteMeters
+	})
+	return places, nil
+}
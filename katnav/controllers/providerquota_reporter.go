@@ -0,0 +1,103 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/quota"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//+kubebuilder:rbac:groups=katnav.fnnrn.me,resources=providerquotas,verbs=get;list;watch;create
+//+kubebuilder:rbac:groups=katnav.fnnrn.me,resources=providerquotas/status,verbs=get;update;patch
+
+// ProviderQuotaReporter publishes the state of the limiter to a ProviderQuota
+// object. It runs with the manager so only the leader reports.
+type ProviderQuotaReporter struct {
+	client.Client
+	Limiter *quota.Limiter
+	// Name is the name of the ProviderQuota object
+	Name string
+	// Interval is how often the status is updated
+	Interval time.Duration
+}
+
+// Start restores the used budget from the last report and then reports until
+// the context is cancelled
+func (q *ProviderQuotaReporter) Start(ctx context.Context) error {
+	log := ctrl.Log.WithName("quota")
+
+	var providerQuota katnavv1.ProviderQuota
+	err := q.Get(ctx, types.NamespacedName{Name: q.Name}, &providerQuota)
+	if errors.IsNotFound(err) {
+		providerQuota.Name = q.Name
+		err = q.Create(ctx, &providerQuota)
+	} else if err == nil {
+		q.Limiter.Restore(providerQuota.Status.Used, providerQuota.Status.ResetTime.Time)
+	}
+	if err != nil {
+		log.Error(err, "unable to find ProviderQuota", "Name", q.Name)
+	}
+
+	ticker := time.NewTicker(q.Interval)
+	defer ticker.Stop()
+	for {
+		if err = q.report(ctx); err != nil {
+			log.Error(err, "unable to update ProviderQuota", "Name", q.Name)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// report writes the current usage to the status of the ProviderQuota
+func (q *ProviderQuotaReporter) report(ctx context.Context) error {
+	var providerQuota katnavv1.ProviderQuota
+	if err := q.Get(ctx, types.NamespacedName{Name: q.Name}, &providerQuota); err != nil {
+		return err
+	}
+
+	qps, budget, used, resetTime := q.Limiter.Usage()
+	status := katnavv1.ProviderQuotaStatus{
+		QPS:            qps,
+		DailyBudget:    budget,
+		Used:           used,
+		ResetTime:      metav1.NewTime(resetTime),
+		LastUpdateTime: providerQuota.Status.LastUpdateTime,
+	}
+	if budget > 0 {
+		remaining := budget - used
+		status.Remaining = &remaining
+	}
+	if providerQuota.Status.Used == status.Used && providerQuota.Status.ResetTime.Equal(&status.ResetTime) &&
+		providerQuota.Status.QPS == status.QPS && providerQuota.Status.DailyBudget == status.DailyBudget {
+		return nil
+	}
+	status.LastUpdateTime = metav1.Now()
+	providerQuota.Status = status
+	return q.Status().Update(ctx, &providerQuota)
+}
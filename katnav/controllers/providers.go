@@ -0,0 +1,268 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/provider"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/quota"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Providers resolves which routing provider an object should use. The Google
+// provider needs an API key that is read from a Secret, the provider is built
+// when it is first needed and rebuilt whenever the Secret changes.
+type Providers struct {
+	client.Client
+
+	// Static are the providers that don't need a key, keyed by name
+	Static map[string]provider.RoutingProvider
+	// Default is the name of the provider used when an object doesn't specify one
+	Default string
+
+	// SecretNamespace, SecretName and SecretKey locate the default Google API key
+	SecretNamespace string
+	SecretName      string
+	SecretKey       string
+
+	// Limiter is shared by every provider to limit the rate and number of requests
+	Limiter *quota.Limiter
+
+	mu        sync.Mutex
+	google    map[googleKey]*googleEntry
+	timeZones map[timeZoneKey]string
+}
+
+// googleKey identifies where an API key was read from
+type googleKey struct {
+	types.NamespacedName
+	key string
+}
+
+// timeZoneKey is a location rounded to roughly 100m
+type timeZoneKey struct {
+	lat, lng int64
+}
+
+// googleEntry is a Google provider and the version of the Secret it was built from
+type googleEntry struct {
+	resourceVersion string
+	provider        *provider.GoogleProvider
+}
+
+// Name returns the name of the provider that will be used, which is the
+// default when name is empty
+func (p *Providers) Name(name string) string {
+	if name == "" {
+		return p.Default
+	}
+	return name
+}
+
+// For returns the named provider (or the default), namespace and secretRef are
+// used to find the API key when the Google provider is needed
+func (p *Providers) For(ctx context.Context, name, namespace string, secretRef *corev1.SecretKeySelector) (provider.RoutingProvider, error) {
+	routingProvider, err := p.provider(ctx, name, namespace, secretRef)
+	if err != nil {
+		return nil, err
+	}
+	if p.Limiter != nil {
+		routingProvider = quota.Wrap(routingProvider, p.Limiter)
+	}
+	return routingProvider, nil
+}
+
+// provider returns the named provider without a limiter
+func (p *Providers) provider(ctx context.Context, name, namespace string, secretRef *corev1.SecretKeySelector) (provider.RoutingProvider, error) {
+	name = p.Name(name)
+	if name == provider.Google {
+		return p.googleProvider(ctx, p.secretFor(namespace, secretRef))
+	}
+	routingProvider, ok := p.Static[name]
+	if !ok {
+		return nil, &provider.Error{Reason: provider.ReasonNotConfigured, Err: fmt.Errorf("routing provider %q is not configured", name)}
+	}
+	return routingProvider, nil
+}
+
+// Geocoder returns the geocoder, only Google is able to geocode so the API
+// key is found in the same way as for routing
+func (p *Providers) Geocoder(ctx context.Context, namespace string, secretRef *corev1.SecretKeySelector) (provider.Geocoder, error) {
+	googleProvider, err := p.googleProvider(ctx, p.secretFor(namespace, secretRef))
+	if err != nil {
+		return nil, err
+	}
+	var geocoder provider.Geocoder = googleProvider
+	if p.Limiter != nil {
+		geocoder = quota.WrapGeocoder(geocoder, p.Limiter)
+	}
+	return geocoder, nil
+}
+
+// Elevation returns the elevation provider, only Google knows the elevation so
+// the API key is found in the same way as for routing
+func (p *Providers) Elevation(ctx context.Context, namespace string, secretRef *corev1.SecretKeySelector) (provider.ElevationProvider, error) {
+	googleProvider, err := p.googleProvider(ctx, p.secretFor(namespace, secretRef))
+	if err != nil {
+		return nil, err
+	}
+	var elevationProvider provider.ElevationProvider = googleProvider
+	if p.Limiter != nil {
+		elevationProvider = quota.WrapElevation(elevationProvider, p.Limiter)
+	}
+	return elevationProvider, nil
+}
+
+// Places returns the places provider, only Google is able to search for
+// places so the API key is found in the same way as for routing
+func (p *Providers) Places(ctx context.Context, namespace string, secretRef *corev1.SecretKeySelector) (provider.PlacesProvider, error) {
+	googleProvider, err := p.googleProvider(ctx, p.secretFor(namespace, secretRef))
+	if err != nil {
+		return nil, err
+	}
+	var placesProvider provider.PlacesProvider = googleProvider
+	if p.Limiter != nil {
+		placesProvider = quota.WrapPlaces(placesProvider, p.Limiter)
+	}
+	return placesProvider, nil
+}
+
+// TimeZone returns the IANA time zone of a location, the time zone of a
+// location doesn't change so they are cached
+func (p *Providers) TimeZone(ctx context.Context, namespace string, secretRef *corev1.SecretKeySelector, coordinates katnavv1.LatLng) (string, error) {
+	key := timeZoneKey{lat: int64(math.Round(coordinates.Lat * 1000)), lng: int64(math.Round(coordinates.Lng * 1000))}
+	p.mu.Lock()
+	zone, ok := p.timeZones[key]
+	p.mu.Unlock()
+	if ok {
+		return zone, nil
+	}
+
+	geocoder, err := p.Geocoder(ctx, namespace, secretRef)
+	if err != nil {
+		return "", err
+	}
+	if zone, err = geocoder.TimeZone(ctx, coordinates, time.Now()); err != nil {
+		return "", err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.timeZones == nil {
+		p.timeZones = map[timeZoneKey]string{}
+	}
+	p.timeZones[key] = zone
+	return zone, nil
+}
+
+// Matrix returns the named provider (or the default) if it is able to build a
+// distance matrix
+func (p *Providers) Matrix(ctx context.Context, name, namespace string, secretRef *corev1.SecretKeySelector) (provider.MatrixProvider, error) {
+	routingProvider, err := p.provider(ctx, name, namespace, secretRef)
+	if err != nil {
+		return nil, err
+	}
+	matrixProvider, ok := routingProvider.(provider.MatrixProvider)
+	if !ok {
+		return nil, &provider.Error{Reason: provider.ReasonNotConfigured, Err: fmt.Errorf("routing provider %q is unable to build a distance matrix", p.Name(name))}
+	}
+	if p.Limiter != nil {
+		matrixProvider = quota.WrapMatrix(matrixProvider, p.Limiter)
+	}
+	return matrixProvider, nil
+}
+
+// JourneyMatrix returns the named provider (or the default) as a distance
+// matrix, a provider that can't build one finds the route of each journey
+func (p *Providers) JourneyMatrix(ctx context.Context, name, namespace string, secretRef *corev1.SecretKeySelector) (provider.MatrixProvider, error) {
+	routingProvider, err := p.provider(ctx, name, namespace, secretRef)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := routingProvider.(provider.MatrixProvider); ok {
+		return p.Matrix(ctx, name, namespace, secretRef)
+	}
+	if p.Limiter != nil {
+		routingProvider = quota.Wrap(routingProvider, p.Limiter)
+	}
+	return provider.RoutedMatrix(routingProvider), nil
+}
+
+// secretFor returns where the API key lives, a secretRef can only refer to a
+// Secret in the same namespace as the object that references it
+func (p *Providers) secretFor(namespace string, secretRef *corev1.SecretKeySelector) googleKey {
+	if secretRef == nil {
+		return googleKey{
+			NamespacedName: types.NamespacedName{Namespace: p.SecretNamespace, Name: p.SecretName},
+			key:            p.SecretKey,
+		}
+	}
+	key := secretRef.Key
+	if key == "" {
+		key = p.SecretKey
+	}
+	return googleKey{
+		NamespacedName: types.NamespacedName{Namespace: namespace, Name: secretRef.Name},
+		key:            key,
+	}
+}
+
+// UsesSecret returns true if an object using the named provider will read its
+// API key from secret
+func (p *Providers) UsesSecret(name, namespace string, secretRef *corev1.SecretKeySelector, secret types.NamespacedName) bool {
+	return p.Name(name) == provider.Google && p.secretFor(namespace, secretRef).NamespacedName == secret
+}
+
+// googleProvider returns the Google provider for the key, it is only rebuilt
+// when the Secret has changed since the provider was created
+func (p *Providers) googleProvider(ctx context.Context, key googleKey) (*provider.GoogleProvider, error) {
+	var secret corev1.Secret
+	if err := p.Get(ctx, key.NamespacedName, &secret); err != nil {
+		// The Secret is watched, so there is no need to retry until it changes
+		return nil, &provider.Error{Reason: provider.ReasonMissingAPIKey, Transient: !errors.IsNotFound(err),
+			Err: fmt.Errorf("unable to read API key from Secret %s: %v", key.NamespacedName, err)}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if entry, ok := p.google[key]; ok && entry.resourceVersion == secret.ResourceVersion {
+		return entry.provider, nil
+	}
+
+	token := string(secret.Data[key.key])
+	if token == "" {
+		return nil, &provider.Error{Reason: provider.ReasonMissingAPIKey,
+			Err: fmt.Errorf("no Token found within key %q of Secret %s", key.key, key.NamespacedName)}
+	}
+	googleProvider, err := provider.NewGoogleProvider(token)
+	if err != nil {
+		return nil, err
+	}
+	if p.google == nil {
+		p.google = map[googleKey]*googleEntry{}
+	}
+	p.google[key] = &googleEntry{resourceVersion: secret.ResourceVersion, provider: googleProvider}
+	return googleProvider, nil
+}
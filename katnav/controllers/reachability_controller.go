@@ -0,0 +1,319 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/provider"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/reachability"
+)
+
+// ReachabilityReconciler reconciles a Reachability object
+type ReachabilityReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// Providers are the same routing backends that Directions use
+	Providers *Providers
+}
+
+//+kubebuilder:rbac:groups=katnav.fnnrn.me,resources=reachabilities,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=katnav.fnnrn.me,resources=reachabilities/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=katnav.fnnrn.me,resources=reachabilities/finalizers,verbs=update
+
+// Reconcile ranks the candidates of a Reachability by how long it takes to
+// reach them, the provider is only queried when the spec changes or the
+// schedule is due
+func (r *ReachabilityReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	var reach katnavv1.Reachability
+	if err := r.Get(ctx, req.NamespacedName, &reach); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "unable to fetch Reachability object")
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	var schedule cron.Schedule
+	if reach.Spec.Schedule != "" {
+		var err error
+		if schedule, err = parseSchedule(reach.Spec.Schedule, reach.Spec.TimeZone); err != nil {
+			reach.Status.Error = err.Error()
+			reach.Status.NextScheduleTime = nil
+			setCondition(&reach.Status.Conditions, reach.Generation, katnavv1.ConditionReady, false, "InvalidSchedule", err.Error())
+			// Nothing will change until the spec is fixed
+			return ctrl.Result{}, r.updateStatus(ctx, &reach)
+		}
+	}
+
+	origin, destinations, err := resolveCandidates(ctx, r.Client, &reach)
+	if err != nil {
+		var locationErr *locationError
+		if !goerrors.As(err, &locationErr) {
+			return ctrl.Result{}, err
+		}
+		return r.rankError(ctx, &reach, schedule, &provider.Error{Reason: locationErr.reason, Err: err})
+	}
+
+	// Queries can cost money, so only ask the provider again if the journeys
+	// have changed, the last attempt failed or the schedule is due
+	hash, err := specHash(struct {
+		Spec         katnavv1.ReachabilitySpec
+		Origin       string
+		Destinations []string
+	}{reach.Spec, origin, destinations})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	now := time.Now()
+	if hash == reach.Status.SpecHash && meta.IsStatusConditionTrue(reach.Status.Conditions, katnavv1.ConditionReady) {
+		if schedule == nil {
+			log.Info("Ranking is up to date")
+			return ctrl.Result{}, nil
+		}
+		if last := reach.Status.LastQueryTime; last != nil {
+			if next := schedule.Next(last.Time); next.After(now) {
+				log.Info("Ranking isn't due", "Next", next)
+				return ctrl.Result{RequeueAfter: next.Sub(now)}, nil
+			}
+		}
+	}
+	reach.Status.SpecHash = hash
+	queried := metav1.NewTime(now)
+	reach.Status.LastQueryTime = &queried
+	reach.Status.NextScheduleTime = nextSchedule(schedule, now)
+
+	matrixProvider, err := r.Providers.JourneyMatrix(ctx, reach.Spec.Provider, reach.Namespace, reach.Spec.SecretRef)
+	if err != nil {
+		return r.rankError(ctx, &reach, schedule, err)
+	}
+	log.Info("Ranking candidates", "Origin", origin, "Candidates", len(destinations))
+	// The journeys leave now, so that the durations are in traffic
+	rows, err := matrixProvider.DistanceMatrix(ctx, &provider.MatrixRequest{
+		Origins:       []string{origin},
+		Destinations:  destinations,
+		Mode:          reach.Spec.Mode,
+		Avoid:         reach.Spec.Avoid,
+		DepartureTime: katnavv1.DepartureTimeNow,
+		TrafficModel:  reach.Spec.TrafficModel,
+		Language:      reach.Spec.Language,
+		Units:         reach.Spec.Units,
+	})
+	if err == nil && len(rows) == 0 {
+		err = &provider.Error{Reason: provider.ReasonZeroResults, Err: goerrors.New("the provider returned no journeys from the origin")}
+	}
+	if err != nil {
+		return r.rankError(ctx, &reach, schedule, err)
+	}
+
+	names := make([]string, len(reach.Spec.Candidates))
+	for x := range reach.Spec.Candidates {
+		names[x] = reach.Spec.Candidates[x].Name
+	}
+	candidates := reachability.Rank(names, rows[0].Elements, reach.Spec.Budget.Duration, reach.Spec.Language)
+	reach.Status.Origin = rows[0].Origin
+	reach.Status.Candidates = candidates
+	reach.Status.Reachable = reachability.Reachable(candidates)
+	reach.Status.Nearest = nearest(candidates)
+	reach.Status.Error = ""
+	withinQuota(&reach.Status.Conditions, reach.Generation)
+	message := fmt.Sprintf("%d of %d candidates reachable within %s", reach.Status.Reachable, len(candidates), reach.Spec.Budget.Duration)
+	setCondition(&reach.Status.Conditions, reach.Generation, katnavv1.ConditionReady, true, "Ranked", message)
+	log.Info("Ranked candidates", "Reachable", reach.Status.Reachable, "Nearest", reach.Status.Nearest)
+
+	if err = r.updateStatus(ctx, &reach); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: untilSchedule(reach.Status.NextScheduleTime, now)}, nil
+}
+
+// nearest returns the name of the candidate that is the quickest to reach, it
+// is empty when no journey was found to any of them
+func nearest(candidates []katnavv1.RankedCandidate) string {
+	if len(candidates) == 0 || candidates[0].Rank != 1 {
+		return ""
+	}
+	return candidates[0].Name
+}
+
+// resolveCandidates returns the origin and the destination of each candidate,
+// any referenced Locations are replaced by their coordinates
+func resolveCandidates(ctx context.Context, c client.Reader, reach *katnavv1.Reachability) (string, []string, error) {
+	origin := reach.Spec.Origin
+	var err error
+	if reach.Spec.OriginRef != nil {
+		if origin, err = locationCoordinates(ctx, c, reach.Namespace, reach.Spec.OriginRef.Name); err != nil {
+			return "", nil, err
+		}
+	}
+	if origin == "" {
+		return "", nil, &locationError{reason: "MissingOrigin", err: fmt.Errorf("either origin or originRef needs to be set")}
+	}
+	destinations := make([]string, len(reach.Spec.Candidates))
+	for x, candidate := range reach.Spec.Candidates {
+		destinations[x] = candidate.Destination
+		if candidate.DestinationRef != nil {
+			if destinations[x], err = locationCoordinates(ctx, c, reach.Namespace, candidate.DestinationRef.Name); err != nil {
+				return "", nil, err
+			}
+		}
+		if destinations[x] == "" {
+			return "", nil, &locationError{reason: "MissingDestination", err: fmt.Errorf("candidate %q needs either a destination or a destinationRef", candidate.Name)}
+		}
+	}
+	return origin, destinations, nil
+}
+
+// rankError records why the candidates couldn't be ranked. Transient errors
+// are retried with a backoff and an exhausted quota once it is reset, anything
+// else waits for the next schedule. Without a schedule it waits for the spec,
+// a referenced Location or the Secret to change, which are all watched.
+func (r *ReachabilityReconciler) rankError(ctx context.Context, reach *katnavv1.Reachability, schedule cron.Schedule, err error) (ctrl.Result, error) {
+	reason, transient := provider.ReasonFor(err)
+	log.FromContext(ctx).Error(err, "unable to rank Reachability", "Reason", reason, "Transient", transient)
+
+	now := time.Now()
+	reach.Status.Error = err.Error()
+	reach.Status.NextScheduleTime = nextSchedule(schedule, now)
+	setCondition(&reach.Status.Conditions, reach.Generation, katnavv1.ConditionReady, false, reason, err.Error())
+	reset, exhausted := quotaExhausted(&reach.Status.Conditions, reach.Generation, err)
+	if updateErr := r.updateStatus(ctx, reach); updateErr != nil {
+		return ctrl.Result{}, updateErr
+	}
+	if exhausted {
+		return ctrl.Result{RequeueAfter: reset}, nil
+	}
+	if transient {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: untilSchedule(reach.Status.NextScheduleTime, now)}, nil
+}
+
+// nextSchedule returns the next time a schedule is due, it is nil when there
+// is no schedule
+func nextSchedule(schedule cron.Schedule, now time.Time) *metav1.Time {
+	if schedule == nil {
+		return nil
+	}
+	next := metav1.NewTime(schedule.Next(now))
+	return &next
+}
+
+// untilSchedule returns how long until the next schedule, zero means that
+// there isn't one
+func untilSchedule(next *metav1.Time, now time.Time) time.Duration {
+	if next == nil {
+		return 0
+	}
+	return next.Sub(now)
+}
+
+// updateStatus writes the status of the Reachability for the generation it describes
+func (r *ReachabilityReconciler) updateStatus(ctx context.Context, reach *katnavv1.Reachability) error {
+	reach.Status.ObservedGeneration = reach.Generation
+	err := r.Client.Status().Update(ctx, reach, &client.UpdateOptions{})
+	if err != nil {
+		log.FromContext(ctx).Error(err, "unable to update reachability")
+	}
+	return err
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ReachabilityReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&katnavv1.Reachability{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(r.secretToReachabilities)).
+		Watches(&source.Kind{Type: &katnavv1.Location{}}, handler.EnqueueRequestsFromMapFunc(r.locationToReachabilities)).
+		Complete(r)
+}
+
+// locationToReachabilities finds every Reachability whose origin or one of
+// its candidates is a Location, so that they are ranked once it is resolved
+// and again when it moves
+func (r *ReachabilityReconciler) locationToReachabilities(obj client.Object) []reconcile.Request {
+	var reachabilities katnavv1.ReachabilityList
+	if err := r.List(context.TODO(), &reachabilities, client.InNamespace(obj.GetNamespace())); err != nil {
+		return nil
+	}
+	var requests []reconcile.Request
+	for x := range reachabilities.Items {
+		reach := &reachabilities.Items[x]
+		if reachabilityUsesLocation(reach, obj.GetName()) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: reach.Namespace, Name: reach.Name},
+			})
+		}
+	}
+	return requests
+}
+
+// reachabilityUsesLocation returns true if the origin or any of the candidates
+// of a Reachability reference the named Location
+func reachabilityUsesLocation(reach *katnavv1.Reachability, name string) bool {
+	if refersTo(reach.Spec.OriginRef, name) {
+		return true
+	}
+	for x := range reach.Spec.Candidates {
+		if refersTo(reach.Spec.Candidates[x].DestinationRef, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// secretToReachabilities finds every Reachability that uses the API key in a
+// Secret, so that the candidates are ranked once it is created or rotated
+func (r *ReachabilityReconciler) secretToReachabilities(obj client.Object) []reconcile.Request {
+	var reachabilities katnavv1.ReachabilityList
+	if err := r.List(context.TODO(), &reachabilities); err != nil {
+		return nil
+	}
+	secret := types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+	var requests []reconcile.Request
+	for x := range reachabilities.Items {
+		reach := &reachabilities.Items[x]
+		if r.Providers.UsesSecret(reach.Spec.Provider, reach.Namespace, reach.Spec.SecretRef, secret) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: reach.Namespace, Name: reach.Name},
+			})
+		}
+	}
+	return requests
+}
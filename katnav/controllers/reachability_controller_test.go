@@ -0,0 +1,114 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+)
+
+func TestUntilSchedule(t *testing.T) {
+	now := time.Date(2021, 6, 1, 8, 0, 0, 0, time.UTC)
+	next := metav1.NewTime(now.Add(90 * time.Minute))
+	for _, test := range []struct {
+		name     string
+		next     *metav1.Time
+		expected time.Duration
+	}{
+		{name: "no schedule"},
+		{name: "scheduled", next: &next, expected: 90 * time.Minute},
+	} {
+		if wait := untilSchedule(test.next, now); wait != test.expected {
+			t.Errorf("%s: expected %s, got %s", test.name, test.expected, wait)
+		}
+	}
+}
+
+func TestNextSchedule(t *testing.T) {
+	now := time.Date(2021, 6, 1, 8, 0, 0, 0, time.UTC)
+	if next := nextSchedule(nil, now); next != nil {
+		t.Errorf("expected no schedule, got %s", next)
+	}
+	schedule, err := parseSchedule("30 9 * * *", "Europe/London")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 09:30 in London is 08:30 UTC during the summer
+	if next := nextSchedule(schedule, now); next == nil || !next.Time.Equal(now.Add(30*time.Minute)) {
+		t.Errorf("unexpected next schedule %v", next)
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	for _, test := range []struct {
+		schedule string
+		timeZone string
+		err      bool
+	}{
+		{schedule: "0 8 * * 1-5"},
+		{schedule: "0 8 * * 1-5", timeZone: "America/New_York"},
+		{schedule: "every morning", err: true},
+		{schedule: "0 8 * * *", timeZone: "Mars/Olympus_Mons", err: true},
+	} {
+		if _, err := parseSchedule(test.schedule, test.timeZone); (err != nil) != test.err {
+			t.Errorf("%q in %q: unexpected error %v", test.schedule, test.timeZone, err)
+		}
+	}
+}
+
+func TestReachabilityUsesLocation(t *testing.T) {
+	reach := &katnavv1.Reachability{Spec: katnavv1.ReachabilitySpec{
+		OriginRef: &corev1.LocalObjectReference{Name: "depot"},
+		Candidates: []katnavv1.ReachabilityCandidate{
+			{Name: "north", Destination: "Leeds"},
+			{Name: "south", DestinationRef: &corev1.LocalObjectReference{Name: "site-south"}},
+		},
+	}}
+	for name, expected := range map[string]bool{"depot": true, "site-south": true, "site-north": false} {
+		if uses := reachabilityUsesLocation(reach, name); uses != expected {
+			t.Errorf("%s: expected %v", name, expected)
+		}
+	}
+}
+
+func TestNearest(t *testing.T) {
+	for _, test := range []struct {
+		name       string
+		candidates []katnavv1.RankedCandidate
+		expected   string
+	}{
+		{name: "no candidates"},
+		{
+			name:       "ranked",
+			candidates: []katnavv1.RankedCandidate{{Name: "north", Rank: 1}, {Name: "south", Rank: 2}},
+			expected:   "north",
+		},
+		{
+			name:       "no journeys",
+			candidates: []katnavv1.RankedCandidate{{Name: "north", ErrorCode: "ZERO_RESULTS"}},
+		},
+	} {
+		if nearest := nearest(test.candidates); nearest != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.name, test.expected, nearest)
+		}
+	}
+}
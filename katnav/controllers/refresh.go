@@ -0,0 +1,76 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+)
+
+// specHash returns a short hash of a spec so that we can tell if it has changed
+// since a provider was last queried
+func specHash(spec interface{}) (string, error) {
+	b, err := json.Marshal(spec)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// refreshAfter returns how long until a refresh is due, zero means that either
+// there is no refresh interval or the refresh is due now. An interval that is
+// shorter than the minimum, which the webhook would have rejected, is raised
+// to it.
+func refreshAfter(interval *metav1.Duration, lastQuery *metav1.Time) time.Duration {
+	if interval == nil || interval.Duration <= 0 || lastQuery == nil {
+		return 0
+	}
+	every := interval.Duration
+	if every < katnavv1.MinRefreshInterval {
+		every = katnavv1.MinRefreshInterval
+	}
+	remaining := time.Until(lastQuery.Add(every))
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// travelTimeHistory is the number of travel times that are kept in the status,
+// a refresh every half an hour will cover a day
+const travelTimeHistory = 48
+
+// recordTravelTime adds a travel time to the history, dropping the oldest once
+// it is full. A cached route will have the same time as the last one recorded
+// so it isn't added again
+func recordTravelTime(history []katnavv1.TravelTime, observed katnavv1.TravelTime) []katnavv1.TravelTime {
+	if len(history) != 0 && history[len(history)-1].Time.Equal(&observed.Time) {
+		return history
+	}
+	history = append(history, observed)
+	if len(history) > travelTimeHistory {
+		history = history[len(history)-travelTimeHistory:]
+	}
+	return history
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+)
+
+func TestRefreshAfter(t *testing.T) {
+	recent := metav1.NewTime(time.Now().Add(-10 * time.Second))
+	old := metav1.NewTime(time.Now().Add(-time.Hour))
+	for _, test := range []struct {
+		name      string
+		interval  *metav1.Duration
+		lastQuery *metav1.Time
+		min, max  time.Duration
+	}{
+		{name: "no interval", lastQuery: &recent},
+		{name: "never queried", interval: &metav1.Duration{Duration: time.Minute}},
+		{name: "due", interval: &metav1.Duration{Duration: time.Minute}, lastQuery: &old},
+		{name: "waiting", interval: &metav1.Duration{Duration: 5 * time.Minute}, lastQuery: &recent, min: 4 * time.Minute, max: 5 * time.Minute},
+		{name: "below the minimum", interval: &metav1.Duration{Duration: time.Second}, lastQuery: &recent, min: 40 * time.Second, max: katnavv1.MinRefreshInterval},
+	} {
+		if refresh := refreshAfter(test.interval, test.lastQuery); refresh < test.min || refresh > test.max {
+			t.Errorf("%s: expected between %s and %s, got %s", test.name, test.min, test.max, refresh)
+		}
+	}
+}
+
+func TestRecordTravelTime(t *testing.T) {
+	start := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	var history []katnavv1.TravelTime
+	for x := 0; x < travelTimeHistory+2; x++ {
+		history = recordTravelTime(history, katnavv1.TravelTime{Time: metav1.NewTime(start.Add(time.Duration(x) * time.Minute))})
+	}
+	if len(history) != travelTimeHistory || !history[0].Time.Time.Equal(start.Add(2*time.Minute)) {
+		t.Errorf("expected the oldest travel times to be dropped, got %d starting at %s", len(history), history[0].Time)
+	}
+	// A cached route has the same time as the last one recorded
+	if again := recordTravelTime(history, history[len(history)-1]); len(again) != len(history) {
+		t.Errorf("expected the same travel time not to be recorded twice")
+	}
+}
+
+func TestSoonest(t *testing.T) {
+	for _, test := range []struct {
+		a, b, expected time.Duration
+	}{
+		{},
+		{a: time.Minute, expected: time.Minute},
+		{b: time.Minute, expected: time.Minute},
+		{a: time.Hour, b: time.Minute, expected: time.Minute},
+		{a: time.Minute, b: time.Hour, expected: time.Minute},
+	} {
+		if soonest := soonest(test.a, test.b); soonest != test.expected {
+			t.Errorf("soonest(%s, %s): expected %s, got %s", test.a, test.b, test.expected, soonest)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/provider"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/routecache"
+)
+
+// routeResult is the routes that were found for a spec
+type routeResult struct {
+	Routes []katnavv1.Route
+	// Created is when the routes were calculated, for cached routes this
+	// will be before the query
+	Created   time.Time
+	FromCache bool
+}
+
+// findRoutes returns the routes for a spec, the cache is used first as long as
+// the entry is newer than maxAge otherwise the provider is queried
+func findRoutes(ctx context.Context, providers *Providers, cache *routecache.Cache, namespace string, spec *katnavv1.DirectionsSpec, maxAge time.Duration) (routeResult, error) {
+	log := log.FromContext(ctx)
+
+	request := provider.NewRequest(spec)
+	var cacheKey string
+	if cache != nil {
+		cacheKey = routecache.Key(providers.Name(spec.Provider), request)
+		entry, found, err := cache.Get(ctx, cacheKey, maxAge)
+		if err != nil {
+			log.Error(err, "unable to read route cache")
+		}
+		if found {
+			log.Info("Using cached route", "Created", entry.Created)
+			return routeResult{Routes: entry.Routes, Created: entry.Created, FromCache: true}, nil
+		}
+	}
+
+	routingProvider, err := providers.For(ctx, spec.Provider, namespace, spec.SecretRef)
+	if err != nil {
+		return routeResult{}, err
+	}
+	result := routeResult{Created: time.Now()}
+	result.Routes, err = routingProvider.Directions(ctx, request)
+	if err != nil {
+		return routeResult{}, err
+	}
+
+	if cache != nil && len(result.Routes) != 0 {
+		if err = cache.Put(ctx, cacheKey, result.Routes); err != nil {
+			log.Error(err, "unable to write route cache")
+		}
+	}
+	return result, nil
+}
+
+// journeyDuration is how long a route is expected to take, using the duration
+// in traffic when the provider returned it
+func journeyDuration(route katnavv1.Route) (string, time.Duration) {
+	if route.DurationInTrafficSeconds != 0 {
+		return route.DurationInTraffic, time.Duration(route.DurationInTrafficSeconds) * time.Second
+	}
+	return route.Duration, time.Duration(route.DurationSeconds) * time.Second
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+)
+
+// defaultSnapshotHistoryLimit is used when the history limit isn't set
+const defaultSnapshotHistoryLimit = 10
+
+// snapshotCreateAttempts is how many times a snapshot is created before giving
+// up, a generated name can occasionally collide with an existing snapshot
+const snapshotCreateAttempts = 3
+
+// snapshotLabel returns the value of the label that a snapshot of a Directions
+// is recorded with. A name that is too long to be a label value is truncated
+// and followed by a hash of the whole name, so that it stays unique.
+func snapshotLabel(name string) string {
+	if len(name) <= validation.LabelValueMaxLength {
+		return name
+	}
+	sum := sha256.Sum256([]byte(name))
+	suffix := "-" + hex.EncodeToString(sum[:4])
+	return name[:validation.LabelValueMaxLength-len(suffix)] + suffix
+}
+
+// snapshotHistoryLimit returns how many snapshots of a Directions are kept
+func snapshotHistoryLimit(directions *katnavv1.Directions) int {
+	if directions.Spec.SnapshotHistoryLimit == nil {
+		return defaultSnapshotHistoryLimit
+	}
+	return int(*directions.Spec.SnapshotHistoryLimit)
+}
+
+// recordSnapshot creates a RouteSnapshot owned by the Directions for a route
+// that has just been found, and removes the oldest snapshots beyond the
+// history limit. A failure is only logged as it doesn't affect the route.
+func (r *DirectionsReconciler) recordSnapshot(ctx context.Context, directions *katnavv1.Directions, route katnavv1.Route, found metav1.Time) {
+	logger := log.FromContext(ctx)
+	limit := snapshotHistoryLimit(directions)
+	if limit > 0 {
+		snapshot := &katnavv1.RouteSnapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				// The API server truncates the prefix so the name is always valid
+				GenerateName: directions.Name + "-",
+				Namespace:    directions.Namespace,
+				Labels:       map[string]string{katnavv1.SnapshotDirectionsLabel: snapshotLabel(directions.Name)},
+			},
+			Spec: katnavv1.RouteSnapshotSpec{
+				Directions:               directions.Name,
+				Time:                     found,
+				Summary:                  route.Summary,
+				StartLocation:            route.StartLocation,
+				EndLocation:              route.EndLocation,
+				Distance:                 route.Distance,
+				DistanceMeters:           route.DistanceMeters,
+				Duration:                 route.Duration,
+				DurationSeconds:          route.DurationSeconds,
+				DurationInTraffic:        route.DurationInTraffic,
+				DurationInTrafficSeconds: route.DurationInTrafficSeconds,
+				OverviewPolyline:         route.OverviewPolyline,
+			},
+		}
+		if err := controllerutil.SetControllerReference(directions, snapshot, r.Scheme); err != nil {
+			logger.Error(err, "unable to record route snapshot")
+			return
+		}
+		if err := r.createSnapshot(ctx, snapshot); err != nil {
+			logger.Error(err, "unable to record route snapshot")
+			return
+		}
+	}
+	if err := r.pruneSnapshots(ctx, directions, limit); err != nil {
+		logger.Error(err, "unable to prune route snapshots")
+	}
+}
+
+// createSnapshot creates a snapshot with a generated name, trying again when
+// the name is already taken
+func (r *DirectionsReconciler) createSnapshot(ctx context.Context, snapshot *katnavv1.RouteSnapshot) error {
+	var err error
+	for x := 0; x < snapshotCreateAttempts; x++ {
+		snapshot.Name = ""
+		err = r.Create(ctx, snapshot)
+		// The API server reports a generated name that already exists as a timeout
+		if !errors.IsAlreadyExists(err) && !errors.IsServerTimeout(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// pruneSnapshots deletes the oldest snapshots of a Directions so that no more
+// than limit are kept
+func (r *DirectionsReconciler) pruneSnapshots(ctx context.Context, directions *katnavv1.Directions, limit int) error {
+	var snapshots katnavv1.RouteSnapshotList
+	if err := r.List(ctx, &snapshots, client.InNamespace(directions.Namespace),
+		client.MatchingLabels{katnavv1.SnapshotDirectionsLabel: snapshotLabel(directions.Name)}); err != nil {
+		return err
+	}
+	for _, snapshot := range snapshotsToPrune(directions, snapshots.Items, limit) {
+		if err := r.Delete(ctx, snapshot); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// snapshotsToPrune returns the oldest snapshots owned by a Directions beyond
+// the history limit
+func snapshotsToPrune(directions *katnavv1.Directions, snapshots []katnavv1.RouteSnapshot, limit int) []*katnavv1.RouteSnapshot {
+	var owned []*katnavv1.RouteSnapshot
+	for x := range snapshots {
+		// Never delete a snapshot that was created by somebody else
+		if metav1.IsControlledBy(&snapshots[x], directions) {
+			owned = append(owned, &snapshots[x])
+		}
+	}
+	if len(owned) <= limit {
+		return nil
+	}
+	sort.Slice(owned, func(i, j int) bool {
+		return owned[i].Spec.Time.Before(&owned[j].Spec.Time)
+	})
+	return owned[:len(owned)-limit]
+}
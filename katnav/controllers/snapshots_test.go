@@ -0,0 +1,88 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+)
+
+func TestSnapshotLabel(t *testing.T) {
+	if label := snapshotLabel("directions-sample"); label != "directions-sample" {
+		t.Errorf("expected a short name to be used as it is, got %q", label)
+	}
+
+	long := strings.Repeat("a", 100)
+	label := snapshotLabel(long + "-home")
+	if errs := validation.IsValidLabelValue(label); len(errs) != 0 {
+		t.Errorf("%q isn't a valid label value: %v", label, errs)
+	}
+	if !strings.HasPrefix(label, long[:50]) {
+		t.Errorf("expected the label to start with the name, got %q", label)
+	}
+	if other := snapshotLabel(long + "-work"); other == label {
+		t.Errorf("expected names with the same prefix to have different labels, got %q", label)
+	}
+}
+
+func TestSnapshotsToPrune(t *testing.T) {
+	directions := &katnavv1.Directions{ObjectMeta: metav1.ObjectMeta{Name: "commute", UID: "directions-uid"}}
+	owner := *metav1.NewControllerRef(directions, katnavv1.GroupVersion.WithKind("Directions"))
+	start := time.Date(2021, 6, 1, 8, 0, 0, 0, time.UTC)
+	snapshot := func(name string, minutes int, owned bool) katnavv1.RouteSnapshot {
+		s := katnavv1.RouteSnapshot{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       katnavv1.RouteSnapshotSpec{Time: metav1.NewTime(start.Add(time.Duration(minutes) * time.Minute))},
+		}
+		if owned {
+			s.OwnerReferences = []metav1.OwnerReference{owner}
+		}
+		return s
+	}
+	// Listed out of order, with the oldest created by somebody else
+	snapshots := []katnavv1.RouteSnapshot{
+		snapshot("third", 30, true),
+		snapshot("first", 10, true),
+		snapshot("manual", 0, false),
+		snapshot("fourth", 40, true),
+		snapshot("second", 20, true),
+	}
+	for _, test := range []struct {
+		limit    int
+		expected []string
+	}{
+		{limit: 10},
+		{limit: 4},
+		{limit: 2, expected: []string{"first", "second"}},
+		{limit: 0, expected: []string{"first", "second", "third", "fourth"}},
+	} {
+		pruned := snapshotsToPrune(directions, snapshots, test.limit)
+		var names []string
+		for _, s := range pruned {
+			names = append(names, s.Name)
+		}
+		if strings.Join(names, ",") != strings.Join(test.expected, ",") {
+			t.Errorf("limit %d: expected %v to be pruned, got %v", test.limit, test.expected, names)
+		}
+	}
+}
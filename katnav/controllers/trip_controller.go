@@ -0,0 +1,374 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/geo"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/provider"
+)
+
+const (
+	// defaultArrivalRadius is used when the arrival radius isn't set
+	defaultArrivalRadius = 100
+	// defaultRecomputeInterval is used when the minimum recompute interval
+	// isn't set
+	defaultRecomputeInterval = time.Minute
+)
+
+// TripReconciler reconciles a Trip object
+type TripReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	Recorder  record.EventRecorder
+	Providers *Providers
+}
+
+//+kubebuilder:rbac:groups=katnav.fnnrn.me,resources=trips,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=katnav.fnnrn.me,resources=trips/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=katnav.fnnrn.me,resources=trips/finalizers,verbs=update
+
+// Reconcile finds the remaining route of a Trip from its latest position, the
+// route is only found again when the position has moved far enough and the
+// last query isn't too recent
+func (r *TripReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	var trip katnavv1.Trip
+	if err := r.Get(ctx, req.NamespacedName, &trip); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "unable to fetch Trip object")
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	original := trip.Status.DeepCopy()
+	now := time.Now()
+
+	// A change to the spec is a new trip
+	if trip.Status.ObservedGeneration != trip.Generation {
+		trip.Status.RoutedPosition = nil
+		trip.Status.DestinationLocation = nil
+		trip.Status.ArrivalTime = nil
+		meta.RemoveStatusCondition(&trip.Status.Conditions, katnavv1.ConditionArrived)
+	} else if meta.IsStatusConditionTrue(trip.Status.Conditions, katnavv1.ConditionArrived) {
+		log.Info("Trip has arrived")
+		return ctrl.Result{}, nil
+	}
+
+	position, changed, err := trip.Position()
+	if err != nil {
+		trip.Status.Error = err.Error()
+		setCondition(&trip.Status.Conditions, trip.Generation, katnavv1.ConditionReady, false, "InvalidPosition", err.Error())
+		return ctrl.Result{}, r.updateStatus(ctx, &trip)
+	}
+	trip.Status.AppliedPositionAnnotation = trip.Annotations[katnavv1.PositionAnnotation]
+	if position == nil {
+		setCondition(&trip.Status.Conditions, trip.Generation, katnavv1.ConditionReady, false, "WaitingForPosition", "the position hasn't been reported")
+		return ctrl.Result{}, r.updateStatus(ctx, &trip)
+	}
+	if changed || position.Time == nil {
+		position = position.DeepCopy()
+		if position.Time == nil {
+			seen := metav1.NewTime(now)
+			position.Time = &seen
+		}
+		trip.Status.Position = position
+	}
+	// A position that has been fixed is ready again once it has a route
+	if ready := meta.FindStatusCondition(trip.Status.Conditions, katnavv1.ConditionReady); ready != nil && ready.Reason == "InvalidPosition" && trip.Status.RoutedPosition != nil {
+		trip.Status.Error = ""
+		setCondition(&trip.Status.Conditions, trip.Generation, katnavv1.ConditionReady, true, "RouteFound", "")
+	}
+
+	// A destination Location that has moved is a new destination, so the
+	// route is found again wherever the position is
+	if ref := trip.Spec.DestinationRef; ref != nil && trip.Status.DestinationLocation != nil {
+		if coordinates, err := locationCoordinates(ctx, r.Client, trip.Namespace, ref.Name); err == nil {
+			if destination, err := katnavv1.ParseLatLng(coordinates); err == nil && destination != *trip.Status.DestinationLocation {
+				log.Info("Destination has moved", "Destination", destination)
+				trip.Status.RoutedPosition = nil
+				trip.Status.DestinationLocation = nil
+			}
+		}
+	}
+
+	if r.arrived(&trip) {
+		return ctrl.Result{}, r.updateStatus(ctx, &trip)
+	}
+
+	// Queries can cost money, so the route is only found again once the
+	// position has moved far enough from where it was last found
+	if routed := trip.Status.RoutedPosition; routed != nil && geo.Distance(*routed, position.LatLng) <= float64(trip.Spec.RecomputeDistanceMeters) {
+		return ctrl.Result{}, r.updateStatusIfChanged(ctx, &trip, original)
+	}
+	if wait := recomputeAfter(&trip, now); wait > 0 {
+		log.Info("Trip has moved, waiting to find the route", "Wait", wait)
+		if err = r.updateStatusIfChanged(ctx, &trip, original); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+
+	log.Info("Determining trip", "Position", position.LatLng, "Destination", trip.Spec.Destination)
+	spec, err := resolveLocations(ctx, r.Client, trip.Namespace, tripDirections(&trip, position.LatLng))
+	if err != nil {
+		var locationErr *locationError
+		if !goerrors.As(err, &locationErr) {
+			return ctrl.Result{}, err
+		}
+		trip.Status.Error = err.Error()
+		setCondition(&trip.Status.Conditions, trip.Generation, katnavv1.ConditionReady, false, locationErr.reason, err.Error())
+		return ctrl.Result{}, r.updateStatus(ctx, &trip)
+	}
+	queried := metav1.NewTime(now)
+	trip.Status.LastQueryTime = &queried
+	// Positions are rarely the same twice, so the route cache isn't used
+	result, err := findRoutes(ctx, r.Providers, nil, trip.Namespace, &spec, 0)
+	if err == nil && len(result.Routes) == 0 {
+		err = &provider.Error{Reason: provider.ReasonZeroResults, Err: goerrors.New("no route could be found between the position and destination")}
+	}
+	if err != nil {
+		return r.routeError(ctx, &trip, err)
+	}
+
+	route := result.Routes[0]
+	duration, expected := journeyDuration(route)
+	eta := metav1.NewTime(now.Add(expected))
+	routed := position.LatLng
+	trip.Status.RoutedPosition = &routed
+	trip.Status.RouteSummary = route.Summary
+	trip.Status.RemainingDistance = route.Distance
+	trip.Status.RemainingDistanceMeters = route.DistanceMeters
+	trip.Status.RemainingDuration = duration
+	trip.Status.RemainingDurationSeconds = int64(expected.Seconds())
+	trip.Status.EstimatedArrivalTime = &eta
+	if destination, err := katnavv1.ParseLatLng(spec.Destination); err == nil {
+		trip.Status.DestinationLocation = &destination
+	} else if len(route.Legs) != 0 && route.Legs[len(route.Legs)-1].EndCoordinates != (katnavv1.LatLng{}) {
+		destination := route.Legs[len(route.Legs)-1].EndCoordinates
+		trip.Status.DestinationLocation = &destination
+	}
+	trip.Status.Error = ""
+	withinQuota(&trip.Status.Conditions, trip.Generation)
+	setCondition(&trip.Status.Conditions, trip.Generation, katnavv1.ConditionReady, true, "RouteFound", "")
+	log.Info("Trip", "Summary", route.Summary, "Remaining", route.Distance, "ETA", eta)
+
+	// The route may have just found where the destination is
+	if !r.arrived(&trip) {
+		setCondition(&trip.Status.Conditions, trip.Generation, katnavv1.ConditionArrived, false, "EnRoute",
+			fmt.Sprintf("%s from the destination", route.Distance))
+	}
+	return ctrl.Result{}, r.updateStatus(ctx, &trip)
+}
+
+// arrived marks a trip as Arrived when its position is within the arrival
+// radius of the destination, it returns false when the destination isn't known
+func (r *TripReconciler) arrived(trip *katnavv1.Trip) bool {
+	destination := trip.Status.DestinationLocation
+	position := trip.Status.Position
+	if destination == nil || position == nil {
+		return false
+	}
+	radius := float64(trip.Spec.ArrivalRadiusMeters)
+	if radius <= 0 {
+		radius = defaultArrivalRadius
+	}
+	distance := geo.Distance(position.LatLng, *destination)
+	if distance > radius {
+		return false
+	}
+
+	message := fmt.Sprintf("%dm from the destination", int(distance))
+	trip.Status.ArrivalTime = position.Time
+	trip.Status.EstimatedArrivalTime = position.Time
+	trip.Status.RemainingDistance = ""
+	trip.Status.RemainingDistanceMeters = 0
+	trip.Status.RemainingDuration = ""
+	trip.Status.RemainingDurationSeconds = 0
+	trip.Status.Error = ""
+	setCondition(&trip.Status.Conditions, trip.Generation, katnavv1.ConditionArrived, true, "WithinArrivalRadius", message)
+	setCondition(&trip.Status.Conditions, trip.Generation, katnavv1.ConditionReady, true, "Arrived", "")
+	r.Recorder.Event(trip, corev1.EventTypeNormal, "Arrived", message)
+	return true
+}
+
+// routeError records why the remaining route couldn't be found, transient
+// errors are returned so that the request is retried with a backoff
+func (r *TripReconciler) routeError(ctx context.Context, trip *katnavv1.Trip, err error) (ctrl.Result, error) {
+	reason, transient := provider.ReasonFor(err)
+	log.FromContext(ctx).Error(err, "unable to fetch Trip", "Reason", reason, "Transient", transient)
+
+	trip.Status.Error = err.Error()
+	setCondition(&trip.Status.Conditions, trip.Generation, katnavv1.ConditionReady, false, reason, err.Error())
+	r.Recorder.Event(trip, corev1.EventTypeWarning, reason, err.Error())
+	reset, exhausted := quotaExhausted(&trip.Status.Conditions, trip.Generation, err)
+	if updateErr := r.updateStatus(ctx, trip); updateErr != nil {
+		return ctrl.Result{}, updateErr
+	}
+	if exhausted {
+		return ctrl.Result{RequeueAfter: reset}, nil
+	}
+	if transient {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// recomputeAfter returns how long to wait before the route of a trip can be
+// found again, zero means that it can be found now
+func recomputeAfter(trip *katnavv1.Trip, now time.Time) time.Duration {
+	if trip.Status.LastQueryTime == nil {
+		return 0
+	}
+	interval := defaultRecomputeInterval
+	if trip.Spec.MinRecomputeInterval != nil {
+		interval = trip.Spec.MinRecomputeInterval.Duration
+	}
+	if wait := trip.Status.LastQueryTime.Add(interval).Sub(now); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// tripDirections returns the journey from the position of a trip to its
+// destination, it leaves now so that the duration is in traffic
+func tripDirections(trip *katnavv1.Trip, position katnavv1.LatLng) katnavv1.DirectionsSpec {
+	return katnavv1.DirectionsSpec{
+		Source:         strconv.FormatFloat(position.Lat, 'f', -1, 64) + "," + strconv.FormatFloat(position.Lng, 'f', -1, 64),
+		Destination:    trip.Spec.Destination,
+		DestinationRef: trip.Spec.DestinationRef,
+		Mode:           trip.Spec.Mode,
+		Avoid:          trip.Spec.Avoid,
+		Provider:       trip.Spec.Provider,
+		SecretRef:      trip.Spec.SecretRef,
+		DepartureTime:  katnavv1.DepartureTimeNow,
+		TrafficModel:   trip.Spec.TrafficModel,
+		Language:       trip.Spec.Language,
+		Region:         trip.Spec.Region,
+		Units:          trip.Spec.Units,
+	}
+}
+
+// updateStatus writes the status of the Trip for the generation it describes
+func (r *TripReconciler) updateStatus(ctx context.Context, trip *katnavv1.Trip) error {
+	trip.Status.ObservedGeneration = trip.Generation
+	err := r.Client.Status().Update(ctx, trip, &client.UpdateOptions{})
+	if err != nil {
+		log.FromContext(ctx).Error(err, "unable to update trip")
+	}
+	return err
+}
+
+// updateStatusIfChanged only writes the status when it is different, so that
+// a position that hasn't moved far doesn't cost a write
+func (r *TripReconciler) updateStatusIfChanged(ctx context.Context, trip *katnavv1.Trip, original *katnavv1.TripStatus) error {
+	if trip.Status.ObservedGeneration == trip.Generation && reflect.DeepEqual(&trip.Status, original) {
+		return nil
+	}
+	return r.updateStatus(ctx, trip)
+}
+
+// tripMoved passes updates that change the spec or the reported position of
+// a Trip, which are the only changes that need the route to be found again
+var tripMoved = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldTrip, ok := e.ObjectOld.(*katnavv1.Trip)
+		if !ok {
+			return false
+		}
+		newTrip, ok := e.ObjectNew.(*katnavv1.Trip)
+		if !ok {
+			return false
+		}
+		return oldTrip.Generation != newTrip.Generation ||
+			oldTrip.Annotations[katnavv1.PositionAnnotation] != newTrip.Annotations[katnavv1.PositionAnnotation] ||
+			!reflect.DeepEqual(oldTrip.Status.Position, newTrip.Status.Position)
+	},
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *TripReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&katnavv1.Trip{}, builder.WithPredicates(tripMoved)).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(r.secretToTrips)).
+		Watches(&source.Kind{Type: &katnavv1.Location{}}, handler.EnqueueRequestsFromMapFunc(r.locationToTrips)).
+		Complete(r)
+}
+
+// locationToTrips finds every Trip whose destination is a Location, so that
+// the route is found once it is resolved and again when it moves
+func (r *TripReconciler) locationToTrips(obj client.Object) []reconcile.Request {
+	var trips katnavv1.TripList
+	if err := r.List(context.TODO(), &trips, client.InNamespace(obj.GetNamespace())); err != nil {
+		return nil
+	}
+	var requests []reconcile.Request
+	for x := range trips.Items {
+		trip := &trips.Items[x]
+		if refersTo(trip.Spec.DestinationRef, obj.GetName()) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: trip.Namespace, Name: trip.Name},
+			})
+		}
+	}
+	return requests
+}
+
+// secretToTrips finds every Trip that uses the API key in a Secret, so that a
+// route that failed without it is found once it is created or rotated
+func (r *TripReconciler) secretToTrips(obj client.Object) []reconcile.Request {
+	var trips katnavv1.TripList
+	if err := r.List(context.TODO(), &trips); err != nil {
+		return nil
+	}
+	secret := types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+	var requests []reconcile.Request
+	for x := range trips.Items {
+		trip := &trips.Items[x]
+		if r.Providers.UsesSecret(trip.Spec.Provider, trip.Namespace, trip.Spec.SecretRef, secret) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: trip.Namespace, Name: trip.Name},
+			})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+)
+
+func TestRecomputeAfter(t *testing.T) {
+	now := time.Date(2021, 6, 1, 8, 0, 0, 0, time.UTC)
+	queried := metav1.NewTime(now.Add(-20 * time.Second))
+	for _, test := range []struct {
+		name      string
+		interval  *metav1.Duration
+		lastQuery *metav1.Time
+		expected  time.Duration
+	}{
+		{name: "never queried"},
+		{name: "default interval", lastQuery: &queried, expected: 40 * time.Second},
+		{name: "short interval", interval: &metav1.Duration{Duration: 10 * time.Second}, lastQuery: &queried},
+		{name: "long interval", interval: &metav1.Duration{Duration: 5 * time.Minute}, lastQuery: &queried, expected: 280 * time.Second},
+	} {
+		trip := &katnavv1.Trip{
+			Spec:   katnavv1.TripSpec{MinRecomputeInterval: test.interval},
+			Status: katnavv1.TripStatus{LastQueryTime: test.lastQuery},
+		}
+		if wait := recomputeAfter(trip, now); wait != test.expected {
+			t.Errorf("%s: expected %s, got %s", test.name, test.expected, wait)
+		}
+	}
+}
@@ -6,7 +6,11 @@ require (
 	github.com/grokify/html-strip-tags-go v0.0.1
 	github.com/onsi/ginkgo v1.14.1
 	github.com/onsi/gomega v1.10.2
+	github.com/prometheus/client_golang v1.7.1
+	github.com/robfig/cron/v3 v3.0.1
+	golang.org/x/time v0.0.0-20200630173020-3af7569d3a1e
 	googlemaps.github.io/maps v1.3.2
+	k8s.io/api v0.20.2
 	k8s.io/apimachinery v0.20.2
 	k8s.io/client-go v0.20.2
 	sigs.k8s.io/controller-runtime v0.8.3
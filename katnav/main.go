@@ -18,13 +18,19 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"os"
+	"strings"
+	"time"
+	// The time zone database is embedded so that local times don't depend on the image
+	_ "time/tzdata"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -33,6 +39,10 @@ import (
 
 	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
 	"github.com/thebsdbox/kubernetes-controllers/katnav/controllers"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/notify"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/provider"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/quota"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/routecache"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -52,11 +62,36 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var routingProvider string
+	var osrmURL string
+	var secretNamespace, secretName, secretKey string
+	var cacheTTL time.Duration
+	var cacheFile, cacheConfigMap string
+	var providerQPS float64
+	var providerBudget int
+	var budgetTimezone, quotaName string
+	var notificationTimeout time.Duration
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8082", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&routingProvider, "routing-provider", provider.Google,
+		"The routing provider used by Directions that don't specify one (google or osrm).")
+	flag.StringVar(&osrmURL, "osrm-url", "", "The address of an OSRM server, e.g. http://osrm:5000. Enables the osrm provider.")
+	flag.StringVar(&secretNamespace, "secret-namespace", "default", "The namespace of the Secret holding the Google API key.")
+	flag.StringVar(&secretName, "secret-name", "katnav", "The name of the Secret holding the Google API key.")
+	flag.StringVar(&secretKey, "secret-key", "directionsKey", "The key within the Secret that holds the Google API key.")
+	flag.DurationVar(&cacheTTL, "route-cache-ttl", time.Hour, "How long routes are cached for, 0 disables the route cache.")
+	flag.StringVar(&cacheFile, "route-cache-file", "", "A file that the route cache is persisted to.")
+	flag.StringVar(&cacheConfigMap, "route-cache-configmap", "",
+		"A ConfigMap (namespace/name) that the route cache is persisted to, this is ignored if a file is set.")
+	flag.Float64Var(&providerQPS, "provider-qps", 10, "The maximum rate of requests to the routing providers, 0 is unlimited.")
+	flag.IntVar(&providerBudget, "provider-daily-budget", 0, "The maximum number of requests to the routing providers each day, 0 is unlimited.")
+	flag.StringVar(&budgetTimezone, "provider-budget-timezone", "UTC",
+		"The time zone whose midnight resets the daily budget, Google quotas reset at midnight America/Los_Angeles.")
+	flag.StringVar(&quotaName, "provider-quota-name", "katnav", "The name of the ProviderQuota object that the budget is reported to.")
+	flag.DurationVar(&notificationTimeout, "notification-timeout", 10*time.Second, "The timeout for each attempt to deliver a notification.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -78,13 +113,121 @@ func main() {
 		os.Exit(1)
 	}
 
+	budgetLocation, err := time.LoadLocation(budgetTimezone)
+	if err != nil {
+		setupLog.Error(err, "invalid budget time zone")
+		os.Exit(1)
+	}
+	limiter := quota.NewLimiter(providerQPS, providerBudget, budgetLocation)
+	if err = mgr.Add(&controllers.ProviderQuotaReporter{
+		Client:   mgr.GetClient(),
+		Limiter:  limiter,
+		Name:     quotaName,
+		Interval: 30 * time.Second,
+	}); err != nil {
+		setupLog.Error(err, "unable to set up quota reporting")
+		os.Exit(1)
+	}
+
+	providers := &controllers.Providers{
+		Client:          mgr.GetClient(),
+		Static:          map[string]provider.RoutingProvider{},
+		Default:         routingProvider,
+		SecretNamespace: secretNamespace,
+		SecretName:      secretName,
+		SecretKey:       secretKey,
+		Limiter:         limiter,
+	}
+	if osrmURL != "" {
+		providers.Static[provider.OSRM] = provider.NewOSRMProvider(osrmURL)
+	}
+
+	var cache *routecache.Cache
+	if cacheTTL > 0 {
+		var store routecache.Store
+		if cacheFile != "" {
+			store = &routecache.FileStore{Path: cacheFile}
+		} else if cacheConfigMap != "" {
+			namespacedName := strings.SplitN(cacheConfigMap, "/", 2)
+			if len(namespacedName) != 2 {
+				setupLog.Error(fmt.Errorf("%q is not namespace/name", cacheConfigMap), "invalid route cache ConfigMap")
+				os.Exit(1)
+			}
+			store = &routecache.ConfigMapStore{
+				Reader:         mgr.GetAPIReader(),
+				Writer:         mgr.GetClient(),
+				NamespacedName: types.NamespacedName{Namespace: namespacedName[0], Name: namespacedName[1]},
+			}
+		}
+		cache = routecache.New(cacheTTL, store)
+	}
+
+	notifier := controllers.NewNotifier(mgr.GetClient(), notify.NewSender(notificationTimeout))
+	if err = mgr.Add(notifier); err != nil {
+		setupLog.Error(err, "unable to add notifier")
+		os.Exit(1)
+	}
+
 	if err = (&controllers.DirectionsReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Providers: providers,
+		Cache:     cache,
+		Notifier:  notifier,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Directions")
 		os.Exit(1)
 	}
+	if err = (&controllers.CommuteReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Recorder:  mgr.GetEventRecorderFor("commute-controller"),
+		Providers: providers,
+		Cache:     cache,
+		Notifier:  notifier,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Commute")
+		os.Exit(1)
+	}
+	if err = (&controllers.LocationReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Providers: providers,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Location")
+		os.Exit(1)
+	}
+	if err = (&controllers.DistanceMatrixReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Providers: providers,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "DistanceMatrix")
+		os.Exit(1)
+	}
+	if err = (&controllers.TripReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Recorder:  mgr.GetEventRecorderFor("trip-controller"),
+		Providers: providers,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Trip")
+		os.Exit(1)
+	}
+	if err = (&controllers.ReachabilityReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Providers: providers,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Reachability")
+		os.Exit(1)
+	}
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = (&katnavv1.Directions{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Directions")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
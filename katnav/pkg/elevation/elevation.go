@@ -0,0 +1,123 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elevation
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/geo"
+)
+
+// MaxSamples is the most points that are sampled along a path, the resolution
+// is increased for paths that would need more
+const MaxSamples = 1024
+
+// Key is the ConfigMap key that the profile is written to
+const Key = "profile.json"
+
+// Point is a single sample along a path
+type Point struct {
+	DistanceMeters  float64 `json:"distanceMeters"`
+	Lat             float64 `json:"lat"`
+	Lng             float64 `json:"lng"`
+	ElevationMeters float64 `json:"elevationMeters"`
+}
+
+// Profile is the elevation sampled along a path
+type Profile struct {
+	ResolutionMeters float64 `json:"resolutionMeters"`
+	Points           []Point `json:"points"`
+}
+
+// Sample returns a profile with a point every resolution meters along the
+// path, the start and end of the path are always sampled
+func Sample(path []katnavv1.LatLng, resolution float64) *Profile {
+	samples, resolution := geo.Interpolate(path, resolution, MaxSamples)
+	profile := &Profile{ResolutionMeters: resolution}
+	for x := range samples {
+		profile.add(samples[x].DistanceMeters, samples[x].Location)
+	}
+	return profile
+}
+
+func (p *Profile) add(along float64, location katnavv1.LatLng) {
+	p.Points = append(p.Points, Point{
+		DistanceMeters: round(along, 10),
+		Lat:            round(location.Lat, 1e5),
+		Lng:            round(location.Lng, 1e5),
+	})
+}
+
+// Locations returns the location of every point in the profile
+func (p *Profile) Locations() []katnavv1.LatLng {
+	locations := make([]katnavv1.LatLng, len(p.Points))
+	for x := range p.Points {
+		locations[x] = katnavv1.LatLng{Lat: p.Points[x].Lat, Lng: p.Points[x].Lng}
+	}
+	return locations
+}
+
+// SetElevations sets the elevation of every point, in the same order as the
+// locations
+func (p *Profile) SetElevations(elevations []float64) error {
+	if len(elevations) != len(p.Points) {
+		return fmt.Errorf("expected %d elevations, got %d", len(p.Points), len(elevations))
+	}
+	for x := range elevations {
+		p.Points[x].ElevationMeters = round(elevations[x], 10)
+	}
+	return nil
+}
+
+// Summary returns the total climbing and the highest and lowest points
+func (p *Profile) Summary() katnavv1.ElevationSummary {
+	summary := katnavv1.ElevationSummary{
+		Samples:          len(p.Points),
+		ResolutionMeters: p.ResolutionMeters,
+	}
+	for x := range p.Points {
+		elevation := p.Points[x].ElevationMeters
+		if x == 0 {
+			summary.MaxMeters = elevation
+			summary.MinMeters = elevation
+			continue
+		}
+		if change := elevation - p.Points[x-1].ElevationMeters; change > 0 {
+			summary.AscentMeters += change
+		} else {
+			summary.DescentMeters -= change
+		}
+		summary.MaxMeters = math.Max(summary.MaxMeters, elevation)
+		summary.MinMeters = math.Min(summary.MinMeters, elevation)
+	}
+	summary.AscentMeters = round(summary.AscentMeters, 10)
+	summary.DescentMeters = round(summary.DescentMeters, 10)
+	return summary
+}
+
+// JSON encodes the profile
+func (p *Profile) JSON() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// round rounds to a fraction, e.g. 10 is a single decimal place
+func round(f, fraction float64) float64 {
+	return math.Round(f*fraction) / fraction
+}
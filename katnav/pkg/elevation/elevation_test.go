@@ -0,0 +1,73 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elevation
+
+import (
+	"math"
+	"testing"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+)
+
+// testPath heads north along the meridian for roughly 1111.95m then east
+var testPath = []katnavv1.LatLng{
+	{Lat: 0, Lng: 0},
+	{Lat: 0.01, Lng: 0},
+	{Lat: 0.01, Lng: 0.005},
+}
+
+func TestSample(t *testing.T) {
+	profile := Sample(testPath, 250)
+	// 1111.95m + 555.97m is sampled every 250m, along with the end
+	if len(profile.Points) != 8 {
+		t.Fatalf("expected 8 points, got %d: %+v", len(profile.Points), profile.Points)
+	}
+	if p := profile.Points[1]; p.DistanceMeters != 250 || p.Lat != 0.00225 || p.Lng != 0 {
+		t.Errorf("unexpected second point %+v", p)
+	}
+	if p := profile.Points[5]; p.DistanceMeters != 1250 || p.Lat != 0.01 || p.Lng != 0.00124 {
+		t.Errorf("unexpected sixth point %+v", p)
+	}
+	last := profile.Points[len(profile.Points)-1]
+	if last.Lat != 0.01 || last.Lng != 0.005 || math.Abs(last.DistanceMeters-1667.9) > 0.2 {
+		t.Errorf("unexpected last point %+v", last)
+	}
+}
+
+func TestSampleLimit(t *testing.T) {
+	profile := Sample(testPath, 1)
+	if len(profile.Points) > MaxSamples {
+		t.Errorf("expected at most %d points, got %d", MaxSamples, len(profile.Points))
+	}
+	if profile.ResolutionMeters <= 1 {
+		t.Errorf("expected the resolution to be increased, got %v", profile.ResolutionMeters)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	profile := &Profile{Points: []Point{{}, {}, {}, {}, {}}}
+	if err := profile.SetElevations([]float64{10}); err == nil {
+		t.Error("expected an error for the wrong number of elevations")
+	}
+	if err := profile.SetElevations([]float64{10, 25.04, 20, 32.5, 5}); err != nil {
+		t.Fatal(err)
+	}
+	summary := profile.Summary()
+	if summary.AscentMeters != 27.5 || summary.DescentMeters != 32.5 || summary.MaxMeters != 32.5 || summary.MinMeters != 5 || summary.Samples != 5 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+}
@@ -0,0 +1,261 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package export
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+	"googlemaps.github.io/maps"
+)
+
+// Track is the path of a single route
+type Track struct {
+	Name   string
+	Points []katnavv1.LatLng
+}
+
+// Waypoint is a named point that the journey passes through
+type Waypoint struct {
+	Name     string
+	Location katnavv1.LatLng
+}
+
+// Export is the geometry of a journey that can be written in each format
+type Export struct {
+	Name      string
+	Tracks    []Track
+	Waypoints []Waypoint
+}
+
+// FromRoutes decodes the path of every route, the waypoints are the start and
+// end of each leg of the first route
+func FromRoutes(name string, routes []katnavv1.Route) (*Export, error) {
+	export := &Export{Name: name}
+	for x := range routes {
+		points, err := RoutePoints(routes[x])
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode route %d: %w", x, err)
+		}
+		trackName := routes[x].Summary
+		if trackName == "" {
+			trackName = fmt.Sprintf("%s route %d", name, x+1)
+		}
+		export.Tracks = append(export.Tracks, Track{Name: trackName, Points: points})
+	}
+	if len(routes) != 0 {
+		legs := routes[0].Legs
+		for x := range legs {
+			export.Waypoints = append(export.Waypoints, Waypoint{Name: legs[x].StartLocation, Location: legs[x].StartCoordinates})
+		}
+		if len(legs) != 0 {
+			last := legs[len(legs)-1]
+			export.Waypoints = append(export.Waypoints, Waypoint{Name: last.EndLocation, Location: last.EndCoordinates})
+		}
+	}
+	return export, nil
+}
+
+// RoutePoints decodes the overview polyline of a route, the polylines of the
+// steps are joined together when there isn't one
+func RoutePoints(route katnavv1.Route) ([]katnavv1.LatLng, error) {
+	polylines := []string{route.OverviewPolyline}
+	if route.OverviewPolyline == "" {
+		polylines = nil
+		for x := range route.Legs {
+			for y := range route.Legs[x].Steps {
+				polylines = append(polylines, route.Legs[x].Steps[y].Polyline)
+			}
+		}
+	}
+	var points []katnavv1.LatLng
+	for _, polyline := range polylines {
+		if polyline == "" {
+			continue
+		}
+		path, err := maps.DecodePolyline(polyline)
+		if err != nil {
+			return nil, err
+		}
+		for x := range path {
+			point := katnavv1.LatLng{Lat: round(path[x].Lat), Lng: round(path[x].Lng)}
+			// The end of one step is the start of the next
+			if len(points) != 0 && points[len(points)-1] == point {
+				continue
+			}
+			points = append(points, point)
+		}
+	}
+	return points, nil
+}
+
+// round removes the floating point noise from decoding, polylines only have
+// five decimal places
+func round(f float64) float64 {
+	return math.Round(f*1e5) / 1e5
+}
+
+// Key returns the ConfigMap key that a format is written to
+func Key(format katnavv1.ExportFormat) string {
+	return "route." + string(format)
+}
+
+// Encode writes the export in a format
+func (e *Export) Encode(format katnavv1.ExportFormat) ([]byte, error) {
+	switch format {
+	case katnavv1.ExportFormatGeoJSON:
+		return e.GeoJSON()
+	case katnavv1.ExportFormatGPX:
+		return e.GPX()
+	case katnavv1.ExportFormatKML:
+		return e.KML()
+	}
+	return nil, fmt.Errorf("unknown export format %q", format)
+}
+
+type geoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+type geoJSONFeature struct {
+	Type       string            `json:"type"`
+	Geometry   geoJSONGeometry   `json:"geometry"`
+	Properties map[string]string `json:"properties"`
+}
+
+type geoJSONGeometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+// geoJSONPosition is longitude then latitude
+func geoJSONPosition(l katnavv1.LatLng) []float64 {
+	return []float64{l.Lng, l.Lat}
+}
+
+// GeoJSON writes a FeatureCollection with a LineString for each track and a
+// Point for each waypoint
+func (e *Export) GeoJSON() ([]byte, error) {
+	collection := geoJSONFeatureCollection{Type: "FeatureCollection", Features: []geoJSONFeature{}}
+	for _, track := range e.Tracks {
+		coordinates := make([][]float64, len(track.Points))
+		for x := range track.Points {
+			coordinates[x] = geoJSONPosition(track.Points[x])
+		}
+		collection.Features = append(collection.Features, geoJSONFeature{
+			Type:       "Feature",
+			Geometry:   geoJSONGeometry{Type: "LineString", Coordinates: coordinates},
+			Properties: map[string]string{"name": track.Name},
+		})
+	}
+	for _, waypoint := range e.Waypoints {
+		collection.Features = append(collection.Features, geoJSONFeature{
+			Type:       "Feature",
+			Geometry:   geoJSONGeometry{Type: "Point", Coordinates: geoJSONPosition(waypoint.Location)},
+			Properties: map[string]string{"name": waypoint.Name},
+		})
+	}
+	return json.MarshalIndent(collection, "", "  ")
+}
+
+type gpx struct {
+	XMLName   xml.Name   `xml:"http://www.topografix.com/GPX/1/1 gpx"`
+	Version   string     `xml:"version,attr"`
+	Creator   string     `xml:"creator,attr"`
+	Name      string     `xml:"metadata>name"`
+	Waypoints []gpxPoint `xml:"wpt"`
+	Tracks    []gpxTrack `xml:"trk"`
+}
+
+type gpxTrack struct {
+	Name   string     `xml:"name"`
+	Points []gpxPoint `xml:"trkseg>trkpt"`
+}
+
+type gpxPoint struct {
+	Lat  float64 `xml:"lat,attr"`
+	Lon  float64 `xml:"lon,attr"`
+	Name string  `xml:"name,omitempty"`
+}
+
+// GPX writes a GPX 1.1 document with a track for each route
+func (e *Export) GPX() ([]byte, error) {
+	doc := gpx{Version: "1.1", Creator: "katnav", Name: e.Name}
+	for _, waypoint := range e.Waypoints {
+		doc.Waypoints = append(doc.Waypoints, gpxPoint{Lat: waypoint.Location.Lat, Lon: waypoint.Location.Lng, Name: waypoint.Name})
+	}
+	for _, track := range e.Tracks {
+		t := gpxTrack{Name: track.Name}
+		for x := range track.Points {
+			t.Points = append(t.Points, gpxPoint{Lat: track.Points[x].Lat, Lon: track.Points[x].Lng})
+		}
+		doc.Tracks = append(doc.Tracks, t)
+	}
+	return marshalXML(doc)
+}
+
+type kml struct {
+	XMLName    xml.Name       `xml:"http://www.opengis.net/kml/2.2 kml"`
+	Name       string         `xml:"Document>name"`
+	Placemarks []kmlPlacemark `xml:"Document>Placemark"`
+}
+
+type kmlPlacemark struct {
+	Name       string       `xml:"name"`
+	Point      *kmlGeometry `xml:"Point,omitempty"`
+	LineString *kmlGeometry `xml:"LineString,omitempty"`
+}
+
+type kmlGeometry struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+// kmlCoordinates is longitude,latitude with each point separated by a space
+func kmlCoordinates(points ...katnavv1.LatLng) string {
+	coordinates := make([]string, len(points))
+	for x := range points {
+		coordinates[x] = strconv.FormatFloat(points[x].Lng, 'f', -1, 64) + "," + strconv.FormatFloat(points[x].Lat, 'f', -1, 64)
+	}
+	return strings.Join(coordinates, " ")
+}
+
+// KML writes a KML document with a LineString for each route and a Point for
+// each waypoint
+func (e *Export) KML() ([]byte, error) {
+	doc := kml{Name: e.Name}
+	for _, track := range e.Tracks {
+		doc.Placemarks = append(doc.Placemarks, kmlPlacemark{Name: track.Name, LineString: &kmlGeometry{Coordinates: kmlCoordinates(track.Points...)}})
+	}
+	for _, waypoint := range e.Waypoints {
+		doc.Placemarks = append(doc.Placemarks, kmlPlacemark{Name: waypoint.Name, Point: &kmlGeometry{Coordinates: kmlCoordinates(waypoint.Location)}})
+	}
+	return marshalXML(doc)
+}
+
+func marshalXML(v interface{}) ([]byte, error) {
+	b, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), b...), nil
+}
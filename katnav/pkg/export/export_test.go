@@ -0,0 +1,138 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package export
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+)
+
+// testRoutes has a single leg, the polyline is the example from the
+// documentation of the polyline algorithm
+var testRoutes = []katnavv1.Route{{
+	Summary:          "Main Street",
+	OverviewPolyline: "_p~iF~ps|U_ulLnnqC_mqNvxq`@",
+	Legs: []katnavv1.Leg{{
+		StartLocation:    "Start",
+		StartCoordinates: katnavv1.LatLng{Lat: 38.5, Lng: -120.2},
+		EndLocation:      "End",
+		EndCoordinates:   katnavv1.LatLng{Lat: 43.252, Lng: -126.453},
+	}},
+}}
+
+func TestFromRoutes(t *testing.T) {
+	e, err := FromRoutes("test", testRoutes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(e.Tracks) != 1 || len(e.Tracks[0].Points) != 3 {
+		t.Fatalf("unexpected tracks %+v", e.Tracks)
+	}
+	if e.Tracks[0].Points[1] != (katnavv1.LatLng{Lat: 40.7, Lng: -120.95}) {
+		t.Errorf("unexpected point %+v", e.Tracks[0].Points[1])
+	}
+	if len(e.Waypoints) != 2 || e.Waypoints[0].Name != "Start" || e.Waypoints[1].Name != "End" {
+		t.Errorf("unexpected waypoints %+v", e.Waypoints)
+	}
+}
+
+func TestFromRoutesSteps(t *testing.T) {
+	route := katnavv1.Route{Legs: []katnavv1.Leg{{Steps: []katnavv1.Step{
+		{Polyline: "_p~iF~ps|U_ulLnnqC"},
+		{Polyline: "_flwFn`faV_mqNvxq`@"},
+	}}}}
+	e, err := FromRoutes("test", []katnavv1.Route{route})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The shared point between the steps is only included once
+	if len(e.Tracks[0].Points) != 3 {
+		t.Errorf("unexpected points %+v", e.Tracks[0].Points)
+	}
+	if e.Tracks[0].Name != "test route 1" {
+		t.Errorf("unexpected name %q", e.Tracks[0].Name)
+	}
+}
+
+func TestGeoJSON(t *testing.T) {
+	e, _ := FromRoutes("test", testRoutes)
+	b, err := e.Encode(katnavv1.ExportFormatGeoJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var collection struct {
+		Features []struct {
+			Geometry struct {
+				Type        string          `json:"type"`
+				Coordinates json.RawMessage `json:"coordinates"`
+			} `json:"geometry"`
+		} `json:"features"`
+	}
+	if err = json.Unmarshal(b, &collection); err != nil {
+		t.Fatal(err)
+	}
+	if len(collection.Features) != 3 || collection.Features[0].Geometry.Type != "LineString" || collection.Features[1].Geometry.Type != "Point" {
+		t.Fatalf("unexpected features %s", b)
+	}
+	// GeoJSON positions are longitude first
+	var point []float64
+	if err = json.Unmarshal(collection.Features[1].Geometry.Coordinates, &point); err != nil {
+		t.Fatal(err)
+	}
+	if len(point) != 2 || point[0] != -120.2 || point[1] != 38.5 {
+		t.Errorf("unexpected point %v", point)
+	}
+}
+
+func TestGPX(t *testing.T) {
+	e, _ := FromRoutes("test", testRoutes)
+	b, err := e.Encode(katnavv1.ExportFormatGPX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc gpx
+	if err = xml.Unmarshal(b, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Waypoints) != 2 || len(doc.Tracks) != 1 || len(doc.Tracks[0].Points) != 3 {
+		t.Fatalf("unexpected document %s", b)
+	}
+	if doc.Tracks[0].Points[0].Lat != 38.5 || doc.Tracks[0].Points[0].Lon != -120.2 {
+		t.Errorf("unexpected point %+v", doc.Tracks[0].Points[0])
+	}
+}
+
+func TestKML(t *testing.T) {
+	e, _ := FromRoutes("test", testRoutes)
+	b, err := e.Encode(katnavv1.ExportFormatKML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"<coordinates>-120.2,38.5 -120.95,40.7 -126.453,43.252</coordinates>",
+		"<Point>",
+		"<name>Main Street</name>",
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("expected %q in %s", want, b)
+		}
+	}
+}
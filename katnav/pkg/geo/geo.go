@@ -0,0 +1,129 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"math"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+)
+
+// earthRadius is the mean radius of the earth in meters
+const earthRadius = 6371008.8
+
+// Sample is a point along a path
+type Sample struct {
+	Location katnavv1.LatLng
+	// DistanceMeters is how far along the path the sample is
+	DistanceMeters float64
+}
+
+// Distance is the great circle distance between two points in meters
+func Distance(a, b katnavv1.LatLng) float64 {
+	lat1, lat2 := radians(a.Lat), radians(b.Lat)
+	dLat := lat2 - lat1
+	dLng := radians(b.Lng - a.Lng)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// Length is the total distance along a path in meters
+func Length(path []katnavv1.LatLng) float64 {
+	var length float64
+	for x := 1; x < len(path); x++ {
+		length += Distance(path[x-1], path[x])
+	}
+	return length
+}
+
+// Interpolate returns a sample every spacing meters along a path, the start
+// and end of the path are always sampled. When more than max samples would be
+// needed the spacing is increased, the spacing that was used is returned.
+func Interpolate(path []katnavv1.LatLng, spacing float64, max int) ([]Sample, float64) {
+	if len(path) == 0 {
+		return nil, spacing
+	}
+
+	// distances are how far along the path each of its points are
+	distances := make([]float64, len(path))
+	for x := 1; x < len(path); x++ {
+		distances[x] = distances[x-1] + Distance(path[x-1], path[x])
+	}
+	total := distances[len(distances)-1]
+	if max > 1 && total/spacing+1 > float64(max) {
+		// Rounded up to a tenth of a meter so that the limit isn't exceeded
+		spacing = math.Ceil(total/float64(max-1)*10) / 10
+	}
+
+	var samples []Sample
+	segment := 0
+	for n := 0; ; n++ {
+		along := float64(n) * spacing
+		if along >= total {
+			break
+		}
+		for segment < len(path)-2 && distances[segment+1] < along {
+			segment++
+		}
+		location := path[segment]
+		if length := distances[segment+1] - distances[segment]; length > 0 {
+			f := (along - distances[segment]) / length
+			location = katnavv1.LatLng{
+				Lat: path[segment].Lat + f*(path[segment+1].Lat-path[segment].Lat),
+				Lng: path[segment].Lng + f*(path[segment+1].Lng-path[segment].Lng),
+			}
+		}
+		samples = append(samples, Sample{Location: location, DistanceMeters: along})
+	}
+	samples = append(samples, Sample{Location: path[len(path)-1], DistanceMeters: total})
+	return samples, spacing
+}
+
+// Nearest finds the closest point on a path to a location, it returns how far
+// the location is from the path and how far along the path the closest point
+// is. Each segment is treated as flat, which is close enough for the short
+// segments of a decoded polyline.
+func Nearest(path []katnavv1.LatLng, location katnavv1.LatLng) (fromPath, along float64) {
+	if len(path) == 0 {
+		return math.Inf(1), 0
+	}
+	fromPath = Distance(path[0], location)
+	var travelled float64
+	for x := 1; x < len(path); x++ {
+		a, b := path[x-1], path[x]
+		length := Distance(a, b)
+		// Project onto the segment in meters around its start
+		scale := math.Cos(radians(a.Lat))
+		bx, by := radians(b.Lng-a.Lng)*scale*earthRadius, radians(b.Lat-a.Lat)*earthRadius
+		px, py := radians(location.Lng-a.Lng)*scale*earthRadius, radians(location.Lat-a.Lat)*earthRadius
+		f := 0.0
+		if d := bx*bx + by*by; d > 0 {
+			f = math.Max(0, math.Min(1, (px*bx+py*by)/d))
+		}
+		closest := katnavv1.LatLng{Lat: a.Lat + f*(b.Lat-a.Lat), Lng: a.Lng + f*(b.Lng-a.Lng)}
+		if d := Distance(closest, location); d < fromPath {
+			fromPath = d
+			along = travelled + f*length
+		}
+		travelled += length
+	}
+	return fromPath, along
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geo
+
+import (
+	"math"
+	"testing"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+)
+
+// testPath heads north along the meridian for roughly 1111.95m then east
+var testPath = []katnavv1.LatLng{
+	{Lat: 0, Lng: 0},
+	{Lat: 0.01, Lng: 0},
+	{Lat: 0.01, Lng: 0.005},
+}
+
+func TestDistance(t *testing.T) {
+	if d := Distance(testPath[0], testPath[1]); math.Abs(d-1111.95) > 0.01 {
+		t.Errorf("unexpected distance %v", d)
+	}
+	if l := Length(testPath); math.Abs(l-1667.93) > 0.01 {
+		t.Errorf("unexpected length %v", l)
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	samples, spacing := Interpolate(testPath, 500, 10)
+	if spacing != 500 || len(samples) != 5 {
+		t.Fatalf("unexpected samples %+v every %v", samples, spacing)
+	}
+	if samples[4].Location != testPath[2] {
+		t.Errorf("expected the last sample to be the end of the path, got %+v", samples[4])
+	}
+
+	samples, spacing = Interpolate(testPath, 100, 5)
+	if len(samples) > 5 || spacing < 416.9 {
+		t.Errorf("expected the spacing to be increased, got %d samples every %v", len(samples), spacing)
+	}
+}
+
+func TestNearest(t *testing.T) {
+	// 0.001 degrees east of halfway along the first segment
+	fromPath, along := Nearest(testPath, katnavv1.LatLng{Lat: 0.005, Lng: 0.001})
+	if math.Abs(fromPath-111.2) > 0.1 || math.Abs(along-555.97) > 0.1 {
+		t.Errorf("unexpected nearest point %v from the path, %v along it", fromPath, along)
+	}
+
+	// Beyond the end of the path
+	fromPath, along = Nearest(testPath, katnavv1.LatLng{Lat: 0.01, Lng: 0.006})
+	if math.Abs(fromPath-111.2) > 0.1 || math.Abs(along-1667.93) > 0.1 {
+		t.Errorf("unexpected nearest point %v from the path, %v along it", fromPath, along)
+	}
+}
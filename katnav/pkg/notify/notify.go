@@ -0,0 +1,149 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+)
+
+// The reasons that a notification is sent
+const (
+	ReasonRouteChanged  = "RouteChanged"
+	ReasonDelayExceeded = "DelayExceeded"
+	ReasonDelayCleared  = "DelayCleared"
+)
+
+// Event is a change to a route that is sent to a sink, it is the body of a
+// webhook notification
+type Event struct {
+	Kind            string    `json:"kind"`
+	Namespace       string    `json:"namespace"`
+	Name            string    `json:"name"`
+	Reason          string    `json:"reason"`
+	Message         string    `json:"message"`
+	Summary         string    `json:"summary,omitempty"`
+	PreviousSummary string    `json:"previousSummary,omitempty"`
+	Duration        string    `json:"duration,omitempty"`
+	DurationSeconds int64     `json:"durationSeconds,omitempty"`
+	Time            time.Time `json:"time"`
+}
+
+// slackMessage is the payload of a Slack incoming webhook
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+// DeliveryError is returned when a sink rejects a notification
+type DeliveryError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *DeliveryError) Error() string {
+	return fmt.Sprintf("sink returned %d: %s", e.StatusCode, e.Body)
+}
+
+// retryable is true for responses where the sink may accept the notification
+// if it is sent again
+func (e *DeliveryError) retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// Sender delivers notifications to sinks
+type Sender struct {
+	Client *http.Client
+}
+
+// NewSender returns a sender with a timeout for each attempt
+func NewSender(timeout time.Duration) *Sender {
+	return &Sender{
+		Client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Deliver makes a single attempt to send an event to a sink
+func (s *Sender) Deliver(ctx context.Context, sinkType katnavv1.SinkType, url string, event Event) error {
+	body, err := payload(sinkType, event)
+	if err != nil {
+		return err
+	}
+	return s.post(ctx, url, body)
+}
+
+// Retryable is true for errors where the sink may accept the notification if
+// it is sent again, only a sink that rejects the request is given up on
+func Retryable(err error) bool {
+	var deliveryErr *DeliveryError
+	if errors.As(err, &deliveryErr) {
+		return deliveryErr.retryable()
+	}
+	return true
+}
+
+// post sends a single request to a sink
+func (s *Sender) post(ctx context.Context, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		return nil
+	}
+	message, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+	return &DeliveryError{StatusCode: resp.StatusCode, Body: string(bytes.TrimSpace(message))}
+}
+
+// payload builds the body of the request for a type of sink
+func payload(sinkType katnavv1.SinkType, event Event) ([]byte, error) {
+	switch sinkType {
+	case katnavv1.SinkTypeSlack:
+		return json.Marshal(slackMessage{Text: slackText(event)})
+	case katnavv1.SinkTypeWebhook, "":
+		return json.Marshal(event)
+	}
+	return nil, fmt.Errorf("unknown sink type %q", sinkType)
+}
+
+// slackText formats an event as a single Slack message
+func slackText(event Event) string {
+	text := fmt.Sprintf("*%s/%s* %s: %s", event.Namespace, event.Name, event.Reason, event.Message)
+	if event.Summary != "" {
+		text += fmt.Sprintf("\nRoute: %s", event.Summary)
+	}
+	if event.Duration != "" {
+		text += fmt.Sprintf("\nDuration: %s", event.Duration)
+	}
+	return text
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+)
+
+// receiver is a local sink that fails the first few requests it is sent
+type receiver struct {
+	mu       sync.Mutex
+	failures int
+	status   int
+	bodies   [][]byte
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var body json.RawMessage
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.bodies = append(r.bodies, body)
+	if len(r.bodies) <= r.failures {
+		http.Error(w, "try again", r.status)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+var testEvent = Event{
+	Kind:            "Directions",
+	Namespace:       "default",
+	Name:            "work",
+	Reason:          ReasonRouteChanged,
+	Message:         "route changed from A1 to M1",
+	Summary:         "M1",
+	PreviousSummary: "A1",
+	Duration:        "45 min",
+	DurationSeconds: 2700,
+}
+
+// result is what a queue recorded for a delivery
+type result struct {
+	attempts int
+	err      error
+}
+
+// deliver sends the test event through a queue and waits for the result
+func deliver(t *testing.T, sinkType katnavv1.SinkType, url string, retries int) result {
+	t.Helper()
+	results := make(chan result, 1)
+	queue := NewQueue(&Sender{Client: http.DefaultClient}, time.Millisecond, 10*time.Millisecond)
+	queue.Resolve = func(ctx context.Context, sink types.NamespacedName) (Target, error) {
+		return Target{Type: sinkType, URL: url, Retries: retries}, nil
+	}
+	queue.Record = func(ctx context.Context, delivery *Delivery, attempts int, err error) {
+		results <- result{attempts: attempts, err: err}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = queue.Start(ctx)
+	}()
+
+	queue.Add(&Delivery{Sink: types.NamespacedName{Namespace: "default", Name: "sink"}, Event: testEvent})
+	select {
+	case r := <-results:
+		return r
+	case <-time.After(5 * time.Second):
+		t.Fatal("the delivery wasn't recorded")
+	}
+	return result{}
+}
+
+func TestDeliverWebhook(t *testing.T) {
+	sink := &receiver{}
+	server := httptest.NewServer(sink)
+	defer server.Close()
+
+	r := deliver(t, katnavv1.SinkTypeWebhook, server.URL, 3)
+	if r.err != nil {
+		t.Fatal(r.err)
+	}
+	if r.attempts != 1 || len(sink.bodies) != 1 {
+		t.Fatalf("expected a single attempt, got %d", r.attempts)
+	}
+	var event Event
+	if err := json.Unmarshal(sink.bodies[0], &event); err != nil {
+		t.Fatal(err)
+	}
+	if event.Reason != ReasonRouteChanged || event.PreviousSummary != "A1" || event.DurationSeconds != 2700 {
+		t.Errorf("unexpected event %+v", event)
+	}
+}
+
+func TestDeliverSlack(t *testing.T) {
+	sink := &receiver{}
+	server := httptest.NewServer(sink)
+	defer server.Close()
+
+	if r := deliver(t, katnavv1.SinkTypeSlack, server.URL, 0); r.err != nil {
+		t.Fatal(r.err)
+	}
+	var message slackMessage
+	if err := json.Unmarshal(sink.bodies[0], &message); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"default/work", "RouteChanged", "Route: M1", "Duration: 45 min"} {
+		if !strings.Contains(message.Text, want) {
+			t.Errorf("expected %q in %q", want, message.Text)
+		}
+	}
+}
+
+func TestDeliverRetries(t *testing.T) {
+	sink := &receiver{failures: 2, status: http.StatusServiceUnavailable}
+	server := httptest.NewServer(sink)
+	defer server.Close()
+
+	r := deliver(t, katnavv1.SinkTypeWebhook, server.URL, 3)
+	if r.err != nil {
+		t.Fatal(r.err)
+	}
+	if r.attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", r.attempts)
+	}
+}
+
+func TestDeliverGivesUp(t *testing.T) {
+	sink := &receiver{failures: 10, status: http.StatusInternalServerError}
+	server := httptest.NewServer(sink)
+	defer server.Close()
+
+	r := deliver(t, katnavv1.SinkTypeWebhook, server.URL, 2)
+	var deliveryErr *DeliveryError
+	if !errors.As(r.err, &deliveryErr) || deliveryErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected a delivery error, got %v", r.err)
+	}
+	if r.attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", r.attempts)
+	}
+}
+
+func TestDeliverDoesNotRetryClientErrors(t *testing.T) {
+	sink := &receiver{failures: 10, status: http.StatusNotFound}
+	server := httptest.NewServer(sink)
+	defer server.Close()
+
+	r := deliver(t, katnavv1.SinkTypeWebhook, server.URL, 3)
+	if r.err == nil {
+		t.Fatal("expected an error")
+	}
+	if r.attempts != 1 {
+		t.Errorf("expected a single attempt, got %d", r.attempts)
+	}
+}
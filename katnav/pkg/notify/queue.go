@@ -0,0 +1,116 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notify
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+)
+
+// queueWorkers is how many deliveries are sent at the same time, so that a
+// slow sink doesn't hold up the others
+const queueWorkers = 4
+
+// Target is where a delivery is sent and how many times it is retried
+type Target struct {
+	Type    katnavv1.SinkType
+	URL     string
+	Retries int
+}
+
+// Delivery is an event waiting to be sent to a sink
+type Delivery struct {
+	Sink  types.NamespacedName
+	Event Event
+}
+
+// Queue sends deliveries in the background so that they don't hold up a
+// reconcile, a failed delivery is put back on the queue with an exponential
+// backoff until it has used all of the retries of its sink
+type Queue struct {
+	Sender *Sender
+	// Resolve looks up the sink of a delivery before each attempt
+	Resolve func(ctx context.Context, sink types.NamespacedName) (Target, error)
+	// Record is called once a delivery has succeeded or been given up on
+	Record func(ctx context.Context, delivery *Delivery, attempts int, err error)
+
+	queue workqueue.RateLimitingInterface
+}
+
+// NewQueue returns a queue that waits backoff before the first retry of a
+// delivery, doubling each time up to maxBackoff
+func NewQueue(sender *Sender, backoff, maxBackoff time.Duration) *Queue {
+	return &Queue{
+		Sender: sender,
+		queue:  workqueue.NewRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(backoff, maxBackoff)),
+	}
+}
+
+// Add queues a delivery to be sent
+func (q *Queue) Add(delivery *Delivery) {
+	q.queue.Add(delivery)
+}
+
+// Start sends deliveries until the context is cancelled, it implements
+// manager.Runnable
+func (q *Queue) Start(ctx context.Context) error {
+	go func() {
+		<-ctx.Done()
+		q.queue.ShutDown()
+	}()
+	var wg sync.WaitGroup
+	for x := 0; x < queueWorkers; x++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for q.process(ctx) {
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// process makes one attempt at the next delivery, it returns false once the
+// queue has been shut down
+func (q *Queue) process(ctx context.Context) bool {
+	item, shutdown := q.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer q.queue.Done(item)
+	delivery := item.(*Delivery)
+
+	attempts := q.queue.NumRequeues(item) + 1
+	target, err := q.Resolve(ctx, delivery.Sink)
+	if err == nil {
+		err = q.Sender.Deliver(ctx, target.Type, target.URL, delivery.Event)
+	}
+	if err != nil && Retryable(err) && attempts <= target.Retries && ctx.Err() == nil {
+		q.queue.AddRateLimited(item)
+		return true
+	}
+	q.queue.Forget(item)
+	q.Record(ctx, delivery, attempts, err)
+	return true
+}
@@ -0,0 +1,31 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+)
+
+// ElevationProvider is a backend that is able to look up the elevation of
+// locations
+type ElevationProvider interface {
+	// Elevation returns the elevation in meters of each location, in the same
+	// order as the locations
+	Elevation(ctx context.Context, locations []katnavv1.LatLng) ([]float64, error)
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"errors"
+	"strings"
+)
+
+// Reasons explain why a provider was unable to return a route, they are used
+// as the reason of a condition so they are CamelCase
+const (
+	ReasonZeroResults            = "ZeroResults"
+	ReasonNotFound               = "NotFound"
+	ReasonInvalidRequest         = "InvalidRequest"
+	ReasonMaxWaypointsExceeded   = "MaxWaypointsExceeded"
+	ReasonMaxRouteLengthExceeded = "MaxRouteLengthExceeded"
+	ReasonOverQueryLimit         = "OverQueryLimit"
+	ReasonOverDailyLimit         = "OverDailyLimit"
+	ReasonRequestDenied          = "RequestDenied"
+	ReasonNotConfigured          = "ProviderNotConfigured"
+	ReasonMissingAPIKey          = "MissingAPIKey"
+	ReasonQuotaExhausted         = "QuotaExhausted"
+	ReasonUnavailable            = "ProviderUnavailable"
+	ReasonUnknownError           = "UnknownError"
+)
+
+// Error is a failure from a provider, Transient errors are expected to go away
+// if the request is tried again later
+type Error struct {
+	Reason    string
+	Transient bool
+	Err       error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ReasonFor returns the reason for an error and if it is transient, errors
+// that haven't come from a provider are treated as the provider being unavailable
+func ReasonFor(err error) (string, bool) {
+	var providerErr *Error
+	if errors.As(err, &providerErr) {
+		return providerErr.Reason, providerErr.Transient
+	}
+	return ReasonUnavailable, true
+}
+
+// googleStatuses maps the status codes of the Google Maps APIs to a reason and
+// if the request is worth trying again
+var googleStatuses = map[string]struct {
+	reason    string
+	transient bool
+}{
+	"ZERO_RESULTS":              {ReasonZeroResults, false},
+	"NOT_FOUND":                 {ReasonNotFound, false},
+	"INVALID_REQUEST":           {ReasonInvalidRequest, false},
+	"MAX_WAYPOINTS_EXCEEDED":    {ReasonMaxWaypointsExceeded, false},
+	"MAX_ROUTE_LENGTH_EXCEEDED": {ReasonMaxRouteLengthExceeded, false},
+	"OVER_QUERY_LIMIT":          {ReasonOverQueryLimit, true},
+	"OVER_DAILY_LIMIT":          {ReasonOverDailyLimit, false},
+	"REQUEST_DENIED":            {ReasonRequestDenied, false},
+	"UNKNOWN_ERROR":             {ReasonUnknownError, true},
+}
+
+// googleError classifies an error from the maps client, which only reports
+// the status code within the text of the error (e.g. "maps: NOT_FOUND - ")
+func googleError(err error) error {
+	status := strings.TrimPrefix(err.Error(), "maps: ")
+	if i := strings.Index(status, " - "); i >= 0 {
+		status = status[:i]
+	}
+	if s, ok := googleStatuses[status]; ok {
+		return &Error{Reason: s.reason, Transient: s.transient, Err: err}
+	}
+	if strings.HasPrefix(err.Error(), "maps: ") {
+		// Anything else the client rejects is a problem with our request
+		return &Error{Reason: ReasonInvalidRequest, Err: err}
+	}
+	return &Error{Reason: ReasonUnavailable, Transient: true, Err: err}
+}
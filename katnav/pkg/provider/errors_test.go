@@ -0,0 +1,42 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGoogleError(t *testing.T) {
+	tests := []struct {
+		err       string
+		reason    string
+		transient bool
+	}{
+		{"maps: NOT_FOUND - ", ReasonNotFound, false},
+		{"maps: OVER_QUERY_LIMIT - You have exceeded your rate-limit", ReasonOverQueryLimit, true},
+		{"maps: REQUEST_DENIED - The provided API key is invalid.", ReasonRequestDenied, false},
+		{"maps: origin missing", ReasonInvalidRequest, false},
+		{"dial tcp: i/o timeout", ReasonUnavailable, true},
+	}
+	for _, test := range tests {
+		reason, transient := ReasonFor(googleError(errors.New(test.err)))
+		if reason != test.reason || transient != test.transient {
+			t.Errorf("%q: got %s (transient %t), expected %s (transient %t)", test.err, reason, transient, test.reason, test.transient)
+		}
+	}
+}
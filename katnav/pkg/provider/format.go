@@ -0,0 +1,124 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+)
+
+// durationUnits are the abbreviations used for hours and minutes in a language
+type durationUnits struct {
+	hour   string
+	minute string
+	// compact joins the numbers and units without spaces, e.g. "1時間23分"
+	compact bool
+}
+
+// languageDurations are keyed by the language without a region, anything that
+// isn't listed uses English
+var languageDurations = map[string]durationUnits{
+	"de": {hour: "Std.", minute: "Min."},
+	"fi": {hour: "t", minute: "min"},
+	"ja": {hour: "時間", minute: "分", compact: true},
+	"ko": {hour: "시간", minute: "분"},
+	"pl": {hour: "godz.", minute: "min"},
+	"ru": {hour: "ч", minute: "мин"},
+	"zh": {hour: "小时", minute: "分钟", compact: true},
+}
+
+// decimalComma are the languages that use a comma as the decimal separator
+var decimalComma = map[string]bool{
+	"da": true, "de": true, "es": true, "fi": true, "fr": true, "it": true, "nb": true,
+	"nl": true, "no": true, "pl": true, "pt": true, "ru": true, "sv": true, "tr": true,
+}
+
+// formatter writes distances and durations in the language and units of a request
+type formatter struct {
+	language string
+	units    katnavv1.Units
+}
+
+// newFormatter returns a formatter for a language such as "en-GB", the region
+// is ignored
+func newFormatter(language string, units katnavv1.Units) formatter {
+	language = strings.ToLower(language)
+	if i := strings.IndexAny(language, "-_"); i >= 0 {
+		language = language[:i]
+	}
+	return formatter{language: language, units: units}
+}
+
+// decimal formats a number with a single decimal place
+func (f formatter) decimal(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 1, 64)
+	if decimalComma[f.language] {
+		s = strings.Replace(s, ".", ",", 1)
+	}
+	return s
+}
+
+// humanDistance returns a distance that can be read from kubectl
+func (f formatter) humanDistance(meters int) string {
+	if f.units == katnavv1.UnitsImperial {
+		miles := float64(meters) / 1609.344
+		if miles < 0.1 {
+			return fmt.Sprintf("%d ft", int(float64(meters)*3.28084+0.5))
+		}
+		return f.decimal(miles) + " mi"
+	}
+	if meters < 1000 {
+		return fmt.Sprintf("%d m", meters)
+	}
+	return f.decimal(float64(meters)/1000) + " km"
+}
+
+// humanDuration returns a duration that can be read from kubectl, e.g.
+// "1 h 23 min". It is rounded to the nearest minute as that is all a journey
+// needs.
+func (f formatter) humanDuration(d time.Duration) string {
+	units, ok := languageDurations[f.language]
+	if !ok {
+		units = durationUnits{hour: "h", minute: "min"}
+	}
+	separator := " "
+	if units.compact {
+		separator = ""
+	}
+
+	d = d.Round(time.Minute)
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+	var parts []string
+	if hours != 0 {
+		parts = append(parts, strconv.Itoa(hours)+separator+units.hour)
+	}
+	if minutes != 0 || hours == 0 {
+		parts = append(parts, strconv.Itoa(minutes)+separator+units.minute)
+	}
+	return strings.Join(parts, separator)
+}
+
+// FormatDuration returns a duration in the same way as the durations that the
+// providers return, e.g. "1 h 23 min" in English
+func FormatDuration(d time.Duration, language string) string {
+	return newFormatter(language, "").humanDuration(d)
+}
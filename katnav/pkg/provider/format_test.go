@@ -0,0 +1,65 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"testing"
+	"time"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+)
+
+func TestHumanDuration(t *testing.T) {
+	for _, test := range []struct {
+		language string
+		duration time.Duration
+		want     string
+	}{
+		{"", 5 * time.Minute, "5 min"},
+		{"", 2 * time.Hour, "2 h"},
+		{"en-GB", 83*time.Minute + 20*time.Second, "1 h 23 min"},
+		{"", 20 * time.Second, "0 min"},
+		{"de", 83 * time.Minute, "1 Std. 23 Min."},
+		{"fr", 83 * time.Minute, "1 h 23 min"},
+		{"ja", 83 * time.Minute, "1時間23分"},
+		{"zh-CN", 2 * time.Hour, "2小时"},
+	} {
+		if got := newFormatter(test.language, "").humanDuration(test.duration); got != test.want {
+			t.Errorf("%q %v: expected %q, got %q", test.language, test.duration, test.want, got)
+		}
+	}
+}
+
+func TestHumanDistance(t *testing.T) {
+	for _, test := range []struct {
+		language string
+		units    katnavv1.Units
+		meters   int
+		want     string
+	}{
+		{"", "", 850, "850 m"},
+		{"", katnavv1.UnitsMetric, 12345, "12.3 km"},
+		{"de", "", 12345, "12,3 km"},
+		{"", katnavv1.UnitsImperial, 100, "328 ft"},
+		{"en-US", katnavv1.UnitsImperial, 16093, "10.0 mi"},
+		{"fr", katnavv1.UnitsImperial, 2414, "1,5 mi"},
+	} {
+		if got := newFormatter(test.language, test.units).humanDistance(test.meters); got != test.want {
+			t.Errorf("%q %s %d: expected %q, got %q", test.language, test.units, test.meters, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"time"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+)
+
+// Place is an address that has been resolved, the name and rating are only
+// set by a places search
+type Place struct {
+	Name             string
+	FormattedAddress string
+	Coordinates      katnavv1.LatLng
+	PlaceID          string
+	Rating           float64
+}
+
+// Geocoder is a backend that is able to resolve addresses and coordinates
+type Geocoder interface {
+	// Geocode returns the best match for an address
+	Geocode(ctx context.Context, address string) (Place, error)
+	// ReverseGeocode returns the closest address to a pair of coordinates
+	ReverseGeocode(ctx context.Context, coordinates katnavv1.LatLng) (Place, error)
+	// TimeZone returns the IANA time zone of a pair of coordinates at a time
+	TimeZone(ctx context.Context, coordinates katnavv1.LatLng, at time.Time) (string, error)
+}
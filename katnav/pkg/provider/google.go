@@ -0,0 +1,378 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	strip "github.com/grokify/html-strip-tags-go"
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+	"googlemaps.github.io/maps"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GoogleProvider finds routes using the Google Maps Directions API
+type GoogleProvider struct {
+	mClient *maps.Client
+}
+
+// NewGoogleProvider creates a provider that authenticates with an API key
+func NewGoogleProvider(key string) (*GoogleProvider, error) {
+	mClient, err := maps.NewClient(maps.WithAPIKey(key))
+	if err != nil {
+		return nil, err
+	}
+	return &GoogleProvider{mClient: mClient}, nil
+}
+
+// Directions will query the Google Maps Directions API
+func (g *GoogleProvider) Directions(ctx context.Context, request *Request) ([]katnavv1.Route, error) {
+	r := &maps.DirectionsRequest{
+		Origin:       request.Origin,
+		Destination:  request.Destination,
+		Mode:         maps.Mode(request.Mode),
+		Alternatives: request.Alternatives,
+		Waypoints:    request.Waypoints,
+		Optimize:     request.OptimizeWaypoints,
+		TrafficModel: maps.TrafficModel(request.TrafficModel),
+		Language:     request.Language,
+		Region:       request.Region,
+		Units:        maps.Units(request.Units),
+	}
+	if request.DepartureTime != "" {
+		r.DepartureTime = googleTime(request.DepartureTime)
+	}
+	if request.ArrivalTime != nil {
+		r.ArrivalTime = strconv.FormatInt(request.ArrivalTime.Unix(), 10)
+	}
+	for x := range request.Avoid {
+		r.Avoid = append(r.Avoid, maps.Avoid(request.Avoid[x]))
+	}
+
+	route, _, err := g.mClient.Directions(ctx, r)
+	if err != nil {
+		return nil, googleError(err)
+	}
+
+	format := newFormatter(request.Language, request.Units)
+	var routes []katnavv1.Route
+	for x := range route {
+		routes = append(routes, googleRoute(route[x], format))
+	}
+	return routes, nil
+}
+
+// MaxMatrixElements is the most elements the Distance Matrix API will return
+// for a single request, larger matrices are split into chunks of origins
+const MaxMatrixElements = 100
+
+// MatrixOriginsPerRequest returns how many origins fit into a single Distance
+// Matrix request, there is always at least one
+func MatrixOriginsPerRequest(destinations int) int {
+	if destinations == 0 || MaxMatrixElements/destinations == 0 {
+		return 1
+	}
+	return MaxMatrixElements / destinations
+}
+
+// DistanceMatrix will query the Google Maps Distance Matrix API, the origins
+// are split across requests to stay within the number of elements allowed
+func (g *GoogleProvider) DistanceMatrix(ctx context.Context, request *MatrixRequest) ([]katnavv1.MatrixRow, error) {
+	avoid := make([]string, len(request.Avoid))
+	for x := range request.Avoid {
+		avoid[x] = string(request.Avoid[x])
+	}
+
+	format := newFormatter(request.Language, request.Units)
+	perRequest := MatrixOriginsPerRequest(len(request.Destinations))
+	var rows []katnavv1.MatrixRow
+	for start := 0; start < len(request.Origins); start += perRequest {
+		end := start + perRequest
+		if end > len(request.Origins) {
+			end = len(request.Origins)
+		}
+		r := &maps.DistanceMatrixRequest{
+			Origins:      request.Origins[start:end],
+			Destinations: request.Destinations,
+			Mode:         maps.Mode(request.Mode),
+			Avoid:        maps.Avoid(strings.Join(avoid, "|")),
+			TrafficModel: maps.TrafficModel(request.TrafficModel),
+			Language:     request.Language,
+			Units:        maps.Units(request.Units),
+		}
+		if request.DepartureTime != "" {
+			r.DepartureTime = googleTime(request.DepartureTime)
+		}
+		response, err := g.mClient.DistanceMatrix(ctx, r)
+		if err != nil {
+			return nil, googleError(err)
+		}
+		rows = append(rows, googleMatrixRows(response, format)...)
+	}
+	return rows, nil
+}
+
+// Geocode will query the Google Maps Geocoding API
+func (g *GoogleProvider) Geocode(ctx context.Context, address string) (Place, error) {
+	results, err := g.mClient.Geocode(ctx, &maps.GeocodingRequest{Address: address})
+	if err != nil {
+		return Place{}, googleError(err)
+	}
+	if len(results) == 0 {
+		return Place{}, &Error{Reason: ReasonZeroResults, Err: fmt.Errorf("no results for %q", address)}
+	}
+	return googlePlace(results[0]), nil
+}
+
+// ReverseGeocode will query the Google Maps Geocoding API for an address
+func (g *GoogleProvider) ReverseGeocode(ctx context.Context, coordinates katnavv1.LatLng) (Place, error) {
+	results, err := g.mClient.ReverseGeocode(ctx, &maps.GeocodingRequest{LatLng: &maps.LatLng{Lat: coordinates.Lat, Lng: coordinates.Lng}})
+	if err != nil {
+		return Place{}, googleError(err)
+	}
+	if len(results) == 0 {
+		return Place{}, &Error{Reason: ReasonZeroResults, Err: fmt.Errorf("no results for %v,%v", coordinates.Lat, coordinates.Lng)}
+	}
+	return googlePlace(results[0]), nil
+}
+
+// TimeZone will query the Google Maps Time Zone API
+func (g *GoogleProvider) TimeZone(ctx context.Context, coordinates katnavv1.LatLng, at time.Time) (string, error) {
+	result, err := g.mClient.Timezone(ctx, &maps.TimezoneRequest{
+		Location:  &maps.LatLng{Lat: coordinates.Lat, Lng: coordinates.Lng},
+		Timestamp: at,
+	})
+	if err != nil {
+		return "", googleError(err)
+	}
+	return result.TimeZoneID, nil
+}
+
+// MaxElevationLocations is the most locations that the Elevation API will
+// accept in a single request, larger requests are split into chunks
+const MaxElevationLocations = 512
+
+// Elevation will query the Google Maps Elevation API, the locations are split
+// across as many requests as are needed
+func (g *GoogleProvider) Elevation(ctx context.Context, locations []katnavv1.LatLng) ([]float64, error) {
+	elevations := make([]float64, 0, len(locations))
+	for start := 0; start < len(locations); start += MaxElevationLocations {
+		end := start + MaxElevationLocations
+		if end > len(locations) {
+			end = len(locations)
+		}
+		request := &maps.ElevationRequest{}
+		for x := start; x < end; x++ {
+			request.Locations = append(request.Locations, maps.LatLng{Lat: locations[x].Lat, Lng: locations[x].Lng})
+		}
+		results, err := g.mClient.Elevation(ctx, request)
+		if err != nil {
+			return nil, googleError(err)
+		}
+		if len(results) != end-start {
+			return nil, &Error{Reason: ReasonUnknownError, Transient: true,
+				Err: fmt.Errorf("expected %d elevations, got %d", end-start, len(results))}
+		}
+		for x := range results {
+			elevations = append(elevations, results[x].Elevation)
+		}
+	}
+	return elevations, nil
+}
+
+// NearbySearch will query the Google Places API, only the first page of
+// results is returned
+func (g *GoogleProvider) NearbySearch(ctx context.Context, request *PlacesRequest) ([]Place, error) {
+	response, err := g.mClient.NearbySearch(ctx, &maps.NearbySearchRequest{
+		Location: &maps.LatLng{Lat: request.Location.Lat, Lng: request.Location.Lng},
+		Radius:   uint(request.RadiusMeters),
+		Type:     maps.PlaceType(request.Type),
+		Keyword:  request.Keyword,
+		Language: request.Language,
+	})
+	if err != nil {
+		return nil, googleError(err)
+	}
+	places := make([]Place, 0, len(response.Results))
+	for _, result := range response.Results {
+		if result.PermanentlyClosed || result.BusinessStatus == "CLOSED_PERMANENTLY" {
+			continue
+		}
+		address := result.FormattedAddress
+		if address == "" {
+			address = result.Vicinity
+		}
+		places = append(places, Place{
+			Name:             result.Name,
+			FormattedAddress: address,
+			Coordinates:      googleLatLng(result.Geometry.Location),
+			PlaceID:          result.PlaceID,
+			Rating:           float64(result.Rating),
+		})
+	}
+	return places, nil
+}
+
+// googlePlace converts a geocoding result
+func googlePlace(result maps.GeocodingResult) Place {
+	return Place{
+		FormattedAddress: result.FormattedAddress,
+		Coordinates:      googleLatLng(result.Geometry.Location),
+		PlaceID:          result.PlaceID,
+	}
+}
+
+// googleMatrixRows converts a distance matrix, the status of each element is
+// converted to the same reasons that are used for errors
+func googleMatrixRows(response *maps.DistanceMatrixResponse, format formatter) []katnavv1.MatrixRow {
+	rows := make([]katnavv1.MatrixRow, len(response.Rows))
+	for x := range response.Rows {
+		if x < len(response.OriginAddresses) {
+			rows[x].Origin = response.OriginAddresses[x]
+		}
+		rows[x].Elements = make([]katnavv1.MatrixElement, len(response.Rows[x].Elements))
+		for y, element := range response.Rows[x].Elements {
+			if y < len(response.DestinationAddresses) {
+				rows[x].Elements[y].Destination = response.DestinationAddresses[y]
+			}
+			if element.Status != "OK" {
+				rows[x].Elements[y].ErrorCode = ReasonUnknownError
+				if s, ok := googleStatuses[element.Status]; ok {
+					rows[x].Elements[y].ErrorCode = s.reason
+				}
+				continue
+			}
+			rows[x].Elements[y].Distance = format.humanDistance(element.Distance.Meters)
+			rows[x].Elements[y].DistanceMeters = element.Distance.Meters
+			rows[x].Elements[y].Duration = format.humanDuration(element.Duration)
+			rows[x].Elements[y].DurationSeconds = int64(element.Duration.Seconds())
+			rows[x].Elements[y].DurationInTrafficSeconds = int64(element.DurationInTraffic.Seconds())
+		}
+	}
+	return rows
+}
+
+// googleRoute builds the status representation of a single route
+func googleRoute(route maps.Route, format formatter) katnavv1.Route {
+	status := katnavv1.Route{
+		Summary:          route.Summary,
+		Warnings:         route.Warnings,
+		WaypointOrder:    route.WaypointOrder,
+		OverviewPolyline: route.OverviewPolyline.Points,
+	}
+	if route.Fare != nil {
+		status.Fare = &katnavv1.Fare{Currency: route.Fare.Currency, Value: route.Fare.Value, Text: route.Fare.Text}
+	}
+	// Each leg only describes part of the journey, so the route is from the
+	// start of the first leg to the end of the last with the totals summed
+	for x := range route.Legs {
+		if x == 0 {
+			status.StartLocation = route.Legs[x].StartAddress
+		}
+		status.EndLocation = route.Legs[x].EndAddress
+		status.DistanceMeters += route.Legs[x].Distance.Meters
+		status.DurationSeconds += int64(route.Legs[x].Duration.Seconds())
+		status.DurationInTrafficSeconds += int64(route.Legs[x].DurationInTraffic.Seconds())
+
+		leg := katnavv1.Leg{
+			StartLocation:    route.Legs[x].StartAddress,
+			EndLocation:      route.Legs[x].EndAddress,
+			StartCoordinates: googleLatLng(route.Legs[x].StartLocation),
+			EndCoordinates:   googleLatLng(route.Legs[x].EndLocation),
+			Distance:         format.humanDistance(route.Legs[x].Distance.Meters),
+			DistanceMeters:   route.Legs[x].Distance.Meters,
+			Duration:         format.humanDuration(route.Legs[x].Duration),
+			DurationSeconds:  int64(route.Legs[x].Duration.Seconds()),
+
+			DurationInTrafficSeconds: int64(route.Legs[x].DurationInTraffic.Seconds()),
+		}
+		for y := range route.Legs[x].Steps {
+			leg.Steps = append(leg.Steps, googleStep(route.Legs[x].Steps[y]))
+		}
+		status.Legs = append(status.Legs, leg)
+	}
+	status.Distance = format.humanDistance(status.DistanceMeters)
+	status.Duration = format.humanDuration(time.Duration(status.DurationSeconds) * time.Second)
+	if status.DurationInTrafficSeconds != 0 {
+		status.DurationInTraffic = format.humanDuration(time.Duration(status.DurationInTrafficSeconds) * time.Second)
+	}
+	return status
+}
+
+// googleTime converts an RFC 3339 time into the seconds since the epoch that
+// the API expects, "now" is passed as it is
+func googleTime(t string) string {
+	parsed, err := time.Parse(time.RFC3339, t)
+	if err != nil {
+		return t
+	}
+	return strconv.FormatInt(parsed.Unix(), 10)
+}
+
+// googleStep builds the status representation of a single step, the maps
+// API doesn't expose the maneuver so that is left empty
+func googleStep(step *maps.Step) katnavv1.Step {
+	status := katnavv1.Step{
+		Instruction:     strip.StripTags(step.HTMLInstructions),
+		TravelMode:      step.TravelMode,
+		DistanceMeters:  step.Distance.Meters,
+		DurationSeconds: int64(step.Duration.Seconds()),
+		StartLocation:   googleLatLng(step.StartLocation),
+		EndLocation:     googleLatLng(step.EndLocation),
+		Polyline:        step.Polyline.Points,
+	}
+	if step.TransitDetails != nil {
+		status.Transit = googleTransit(step.TransitDetails)
+	}
+	return status
+}
+
+// googleTransit converts the details of a transit step
+func googleTransit(transit *maps.TransitDetails) *katnavv1.TransitDetails {
+	details := &katnavv1.TransitDetails{
+		LineName:      transit.Line.Name,
+		LineShortName: transit.Line.ShortName,
+		VehicleType:   transit.Line.Vehicle.Type,
+		Headsign:      transit.Headsign,
+		DepartureStop: katnavv1.TransitStop{Name: transit.DepartureStop.Name, Location: googleLatLng(transit.DepartureStop.Location)},
+		ArrivalStop:   katnavv1.TransitStop{Name: transit.ArrivalStop.Name, Location: googleLatLng(transit.ArrivalStop.Location)},
+		NumStops:      int(transit.NumStops),
+	}
+	for _, agency := range transit.Line.Agencies {
+		if agency != nil {
+			details.Agencies = append(details.Agencies, agency.Name)
+		}
+	}
+	if !transit.DepartureTime.IsZero() {
+		departure := metav1.NewTime(transit.DepartureTime)
+		details.DepartureTime = &departure
+	}
+	if !transit.ArrivalTime.IsZero() {
+		arrival := metav1.NewTime(transit.ArrivalTime)
+		details.ArrivalTime = &arrival
+	}
+	return details
+}
+
+// googleLatLng converts coordinates from the maps API
+func googleLatLng(l maps.LatLng) katnavv1.LatLng {
+	return katnavv1.LatLng{Lat: l.Lat, Lng: l.Lng}
+}
@@ -0,0 +1,232 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+	"googlemaps.github.io/maps"
+)
+
+// testGoogleProvider returns a provider that sends its requests to a handler
+func testGoogleProvider(t *testing.T, handler http.HandlerFunc) *GoogleProvider {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	mClient, err := maps.NewClient(maps.WithAPIKey("test"), maps.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &GoogleProvider{mClient: mClient}
+}
+
+func TestGoogleDistanceMatrix(t *testing.T) {
+	requests := 0
+	g := testGoogleProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		origins := strings.Split(r.URL.Query().Get("origins"), "|")
+		destinations := strings.Split(r.URL.Query().Get("destinations"), "|")
+		if avoid := r.URL.Query().Get("avoid"); avoid != "tolls|ferries" {
+			t.Errorf("unexpected avoid %q", avoid)
+		}
+
+		response := map[string]interface{}{"status": "OK", "origin_addresses": origins, "destination_addresses": destinations}
+		var rows []interface{}
+		for range origins {
+			var elements []interface{}
+			for y := range destinations {
+				if y == 0 {
+					elements = append(elements, map[string]interface{}{"status": "ZERO_RESULTS"})
+					continue
+				}
+				elements = append(elements, map[string]interface{}{
+					"status":   "OK",
+					"distance": map[string]interface{}{"value": 1500, "text": "1.5 km"},
+					"duration": map[string]interface{}{"value": 300, "text": "5 mins"},
+				})
+			}
+			rows = append(rows, map[string]interface{}{"elements": elements})
+		}
+		response["rows"] = rows
+		_ = json.NewEncoder(w).Encode(response)
+	})
+
+	request := &MatrixRequest{Mode: katnavv1.TravelModeDriving, Avoid: []katnavv1.Avoid{katnavv1.AvoidTolls, katnavv1.AvoidFerries}}
+	for x := 0; x < 3; x++ {
+		request.Origins = append(request.Origins, fmt.Sprintf("origin %d", x))
+	}
+	for x := 0; x < 40; x++ {
+		request.Destinations = append(request.Destinations, fmt.Sprintf("destination %d", x))
+	}
+
+	rows, err := g.DistanceMatrix(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Only two origins fit in a request with 40 destinations
+	if requests != 2 {
+		t.Errorf("expected 2 requests, got %d", requests)
+	}
+	if len(rows) != 3 || rows[2].Origin != "origin 2" || len(rows[2].Elements) != 40 {
+		t.Fatalf("unexpected rows %+v", rows)
+	}
+	if rows[0].Elements[0].ErrorCode != ReasonZeroResults {
+		t.Errorf("expected %s, got %q", ReasonZeroResults, rows[0].Elements[0].ErrorCode)
+	}
+	if element := rows[1].Elements[1]; element.ErrorCode != "" || element.DistanceMeters != 1500 || element.DurationSeconds != 300 || element.Duration != "5 min" {
+		t.Errorf("unexpected element %+v", element)
+	}
+}
+
+const googleTransitResponse = `{
+  "status": "OK",
+  "routes": [{
+    "summary": "",
+    "fare": {"currency": "GBP", "value": 2.8, "text": "£2.80"},
+    "overview_polyline": {"points": "_p~iF~ps|U_ulLnnqC"},
+    "legs": [{
+      "start_address": "Bond Street", "end_address": "Canary Wharf",
+      "distance": {"value": 9000, "text": "9 km"}, "duration": {"value": 1020, "text": "17 mins"},
+      "steps": [{
+        "html_instructions": "Subway towards <b>Stratford</b>", "travel_mode": "TRANSIT",
+        "distance": {"value": 9000, "text": "9 km"}, "duration": {"value": 1020, "text": "17 mins"},
+        "transit_details": {
+          "arrival_stop": {"name": "Canary Wharf", "location": {"lat": 51.503, "lng": -0.018}},
+          "departure_stop": {"name": "Bond Street", "location": {"lat": 51.514, "lng": -0.149}},
+          "arrival_time": {"text": "8:32am", "time_zone": "Europe/London", "value": 1622619120},
+          "departure_time": {"text": "8:15am", "time_zone": "Europe/London", "value": 1622618100},
+          "headsign": "Stratford",
+          "num_stops": 6,
+          "line": {"name": "Jubilee", "vehicle": {"name": "Subway", "type": "SUBWAY"}, "agencies": [{"name": "Transport for London"}]}
+        }
+      }]
+    }]
+  }]
+}`
+
+func TestGoogleTransitDirections(t *testing.T) {
+	g := testGoogleProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if mode := r.URL.Query().Get("mode"); mode != "transit" {
+			t.Errorf("unexpected mode %q", mode)
+		}
+		_, _ = w.Write([]byte(googleTransitResponse))
+	})
+
+	routes, err := g.Directions(context.Background(), &Request{Origin: "Bond Street", Destination: "Canary Wharf", Mode: katnavv1.TravelModeTransit, Units: katnavv1.UnitsImperial})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 1 || len(routes[0].Legs) != 1 || len(routes[0].Legs[0].Steps) != 1 {
+		t.Fatalf("unexpected routes %+v", routes)
+	}
+	// The leg is formatted in the same units as the route rather than the text
+	// that Google returned
+	if routes[0].Legs[0].Distance != "5.6 mi" || routes[0].Distance != routes[0].Legs[0].Distance {
+		t.Errorf("unexpected distances %q and %q", routes[0].Distance, routes[0].Legs[0].Distance)
+	}
+	if fare := routes[0].Fare; fare == nil || fare.Currency != "GBP" || fare.Value != 2.8 || fare.Text != "£2.80" {
+		t.Errorf("unexpected fare %+v", fare)
+	}
+	if routes[0].OverviewPolyline != "_p~iF~ps|U_ulLnnqC" {
+		t.Errorf("unexpected overview polyline %q", routes[0].OverviewPolyline)
+	}
+	transit := routes[0].Legs[0].Steps[0].Transit
+	if transit == nil {
+		t.Fatal("expected transit details")
+	}
+	if transit.LineName != "Jubilee" || transit.VehicleType != "SUBWAY" || transit.Headsign != "Stratford" || transit.NumStops != 6 {
+		t.Errorf("unexpected transit details %+v", transit)
+	}
+	if len(transit.Agencies) != 1 || transit.Agencies[0] != "Transport for London" {
+		t.Errorf("unexpected agencies %v", transit.Agencies)
+	}
+	if transit.DepartureStop.Name != "Bond Street" || transit.ArrivalStop.Location.Lat != 51.503 {
+		t.Errorf("unexpected stops %+v %+v", transit.DepartureStop, transit.ArrivalStop)
+	}
+	if transit.DepartureTime == nil || transit.DepartureTime.Unix() != 1622618100 || transit.ArrivalTime == nil || transit.ArrivalTime.Unix() != 1622619120 {
+		t.Errorf("unexpected times %v %v", transit.DepartureTime, transit.ArrivalTime)
+	}
+}
+
+func TestGoogleElevation(t *testing.T) {
+	var requests []int
+	g := testGoogleProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		path, err := maps.DecodePolyline(strings.TrimPrefix(r.URL.Query().Get("locations"), "enc:"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		requests = append(requests, len(path))
+		var results []interface{}
+		for x := range path {
+			results = append(results, map[string]interface{}{"elevation": path[x].Lat * 10})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": "OK", "results": results})
+	})
+
+	var locations []katnavv1.LatLng
+	for x := 0; x < 600; x++ {
+		locations = append(locations, katnavv1.LatLng{Lat: float64(x) / 100, Lng: 0})
+	}
+	elevations, err := g.Elevation(context.Background(), locations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 2 || requests[0] != MaxElevationLocations || requests[1] != 600-MaxElevationLocations {
+		t.Errorf("expected the locations to be split across two requests, got %v", requests)
+	}
+	if len(elevations) != 600 || elevations[599] < 59.8 || elevations[599] > 60 {
+		t.Errorf("unexpected elevations %d, last %v", len(elevations), elevations[len(elevations)-1])
+	}
+}
+
+func TestGoogleNearbySearch(t *testing.T) {
+	g := testGoogleProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("location") != "51.5,-0.1" || q.Get("radius") != "800" || q.Get("type") != "gas_station" {
+			t.Errorf("unexpected query %v", q)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": "OK", "results": []interface{}{
+			map[string]interface{}{
+				"name":     "Fuel Stop",
+				"place_id": "abc",
+				"vicinity": "1 High Street",
+				"rating":   4.5,
+				"geometry": map[string]interface{}{"location": map[string]interface{}{"lat": 51.501, "lng": -0.1}},
+			},
+			map[string]interface{}{"name": "Closed", "business_status": "CLOSED_PERMANENTLY"},
+		}})
+	})
+
+	places, err := g.NearbySearch(context.Background(), &PlacesRequest{
+		Location:     katnavv1.LatLng{Lat: 51.5, Lng: -0.1},
+		RadiusMeters: 800,
+		Type:         "gas_station",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Place{Name: "Fuel Stop", FormattedAddress: "1 High Street", Coordinates: katnavv1.LatLng{Lat: 51.501, Lng: -0.1}, PlaceID: "abc", Rating: 4.5}
+	if len(places) != 1 || places[0] != want {
+		t.Errorf("unexpected places %+v", places)
+	}
+}
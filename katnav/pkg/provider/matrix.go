@@ -0,0 +1,120 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+)
+
+// MatrixProvider is a backend that is able to find the journeys between many
+// origins and destinations at once
+type MatrixProvider interface {
+	// DistanceMatrix returns a row for each origin with an element for each
+	// destination, problems with a single journey are reported on its element
+	DistanceMatrix(ctx context.Context, request *MatrixRequest) ([]katnavv1.MatrixRow, error)
+}
+
+// MatrixRequest is everything a provider needs to know to build a matrix
+type MatrixRequest struct {
+	Origins      []string
+	Destinations []string
+	Mode         katnavv1.TravelMode
+	Avoid        []katnavv1.Avoid
+	// DepartureTime is "now" or an RFC 3339 time
+	DepartureTime string
+	TrafficModel  katnavv1.TrafficModel
+	Language      string
+	Units         katnavv1.Units
+}
+
+// NewMatrixRequest builds a request for one of the modes of a DistanceMatrix
+func NewMatrixRequest(spec *katnavv1.DistanceMatrixSpec, mode katnavv1.TravelMode) *MatrixRequest {
+	return &MatrixRequest{
+		Origins:       spec.Origins,
+		Destinations:  spec.Destinations,
+		Mode:          mode,
+		Avoid:         spec.Avoid,
+		DepartureTime: spec.DepartureTime,
+		TrafficModel:  spec.TrafficModel,
+		Language:      spec.Language,
+		Units:         spec.Units,
+	}
+}
+
+// RoutedMatrix builds a matrix from a provider that is only able to find
+// routes, the route of every journey is found on its own
+func RoutedMatrix(routingProvider RoutingProvider) MatrixProvider {
+	return &routedMatrix{routingProvider: routingProvider}
+}
+
+type routedMatrix struct {
+	routingProvider RoutingProvider
+}
+
+// DistanceMatrix finds the route from each origin to each destination, a
+// journey that the provider rejects is reported on its element in the same way
+// as Google. Transient errors and an exhausted quota fail the whole matrix so
+// that it is tried again.
+func (m *routedMatrix) DistanceMatrix(ctx context.Context, request *MatrixRequest) ([]katnavv1.MatrixRow, error) {
+	rows := make([]katnavv1.MatrixRow, len(request.Origins))
+	for x, origin := range request.Origins {
+		rows[x] = katnavv1.MatrixRow{Origin: origin, Elements: make([]katnavv1.MatrixElement, len(request.Destinations))}
+		for y, destination := range request.Destinations {
+			element := &rows[x].Elements[y]
+			element.Destination = destination
+			routes, err := m.routingProvider.Directions(ctx, &Request{
+				Origin:        origin,
+				Destination:   destination,
+				Mode:          request.Mode,
+				Avoid:         request.Avoid,
+				DepartureTime: request.DepartureTime,
+				TrafficModel:  request.TrafficModel,
+				Language:      request.Language,
+				Units:         request.Units,
+			})
+			if err != nil {
+				// An exhausted quota would fail every other element too, so the
+				// whole matrix fails and is tried again once the quota resets
+				reason, transient := ReasonFor(err)
+				if transient || reason == ReasonQuotaExhausted {
+					return nil, err
+				}
+				element.ErrorCode = reason
+				continue
+			}
+			if len(routes) == 0 {
+				element.ErrorCode = ReasonZeroResults
+				continue
+			}
+			route := routes[0]
+			if route.StartLocation != "" {
+				rows[x].Origin = route.StartLocation
+			}
+			if route.EndLocation != "" {
+				element.Destination = route.EndLocation
+			}
+			element.Distance = route.Distance
+			element.DistanceMeters = route.DistanceMeters
+			element.Duration = route.Duration
+			element.DurationSeconds = route.DurationSeconds
+			element.DurationInTrafficSeconds = route.DurationInTrafficSeconds
+		}
+	}
+	return rows, nil
+}
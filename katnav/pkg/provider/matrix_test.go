@@ -0,0 +1,89 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+)
+
+// fakeRouting returns a route, or an error, for each destination
+type fakeRouting struct {
+	routes map[string][]katnavv1.Route
+	errs   map[string]error
+}
+
+func (f *fakeRouting) Directions(ctx context.Context, request *Request) ([]katnavv1.Route, error) {
+	if err := f.errs[request.Destination]; err != nil {
+		return nil, err
+	}
+	return f.routes[request.Destination], nil
+}
+
+func TestRoutedMatrix(t *testing.T) {
+	routing := &fakeRouting{
+		routes: map[string][]katnavv1.Route{
+			"1,1": {{StartLocation: "Origin Street", EndLocation: "First Street", Distance: "1.5 km", DistanceMeters: 1500, Duration: "5 mins", DurationSeconds: 300}},
+		},
+		errs: map[string]error{
+			"3,3": &Error{Reason: ReasonInvalidRequest, Err: errors.New("rejected")},
+		},
+	}
+	rows, err := RoutedMatrix(routing).DistanceMatrix(context.TODO(), &MatrixRequest{
+		Origins:      []string{"0,0"},
+		Destinations: []string{"1,1", "2,2", "3,3"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || len(rows[0].Elements) != 3 {
+		t.Fatalf("expected 1 row of 3 elements, got %+v", rows)
+	}
+	if rows[0].Origin != "Origin Street" {
+		t.Errorf("expected the origin address, got %q", rows[0].Origin)
+	}
+	if e := rows[0].Elements[0]; e.Destination != "First Street" || e.DistanceMeters != 1500 || e.DurationSeconds != 300 || e.ErrorCode != "" {
+		t.Errorf("unexpected element %+v", e)
+	}
+	if e := rows[0].Elements[1]; e.Destination != "2,2" || e.ErrorCode != ReasonZeroResults {
+		t.Errorf("expected no route to be found, got %+v", e)
+	}
+	if e := rows[0].Elements[2]; e.ErrorCode != ReasonInvalidRequest {
+		t.Errorf("expected the request to be rejected, got %+v", e)
+	}
+
+	routing.errs["2,2"] = &Error{Reason: ReasonUnavailable, Transient: true, Err: errors.New("unavailable")}
+	if _, err = RoutedMatrix(routing).DistanceMatrix(context.TODO(), &MatrixRequest{
+		Origins:      []string{"0,0"},
+		Destinations: []string{"1,1", "2,2"},
+	}); err == nil {
+		t.Error("expected a transient error to fail the matrix")
+	}
+
+	// The quota limiter reports an exhausted budget as a non-transient error
+	exhausted := &Error{Reason: ReasonQuotaExhausted, Err: errors.New("daily budget exhausted")}
+	routing.errs["2,2"] = exhausted
+	if _, err = RoutedMatrix(routing).DistanceMatrix(context.TODO(), &MatrixRequest{
+		Origins:      []string{"0,0"},
+		Destinations: []string{"1,1", "2,2"},
+	}); err != exhausted {
+		t.Errorf("expected an exhausted quota to fail the matrix, got %v", err)
+	}
+}
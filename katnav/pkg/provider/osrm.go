@@ -0,0 +1,303 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+	"googlemaps.github.io/maps"
+)
+
+// OSRMProvider finds routes using the HTTP API of an Open Source Routing Machine,
+// as OSRM has no geocoder every location needs to be a "lat,lng" pair
+type OSRMProvider struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewOSRMProvider creates a provider for the OSRM server at baseURL
+func NewOSRMProvider(baseURL string) *OSRMProvider {
+	return &OSRMProvider{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// osrmProfiles maps our travel modes to the profiles that ship with OSRM
+var osrmProfiles = map[katnavv1.TravelMode]string{
+	katnavv1.TravelModeDriving:   "car",
+	katnavv1.TravelModeWalking:   "foot",
+	katnavv1.TravelModeBicycling: "bike",
+}
+
+// osrmExcludes maps our avoid options to the classes used by the OSRM car profile
+var osrmExcludes = map[katnavv1.Avoid]string{
+	katnavv1.AvoidTolls:    "toll",
+	katnavv1.AvoidHighways: "motorway",
+	katnavv1.AvoidFerries:  "ferry",
+}
+
+type osrmResponse struct {
+	Code      string         `json:"code"`
+	Message   string         `json:"message"`
+	Routes    []osrmRoute    `json:"routes"`
+	Trips     []osrmRoute    `json:"trips"`
+	Waypoints []osrmWaypoint `json:"waypoints"`
+}
+
+type osrmWaypoint struct {
+	Name          string     `json:"name"`
+	Location      [2]float64 `json:"location"`
+	WaypointIndex int        `json:"waypoint_index"`
+}
+
+type osrmRoute struct {
+	Distance float64   `json:"distance"`
+	Duration float64   `json:"duration"`
+	Geometry string    `json:"geometry"`
+	Legs     []osrmLeg `json:"legs"`
+}
+
+type osrmLeg struct {
+	Summary  string     `json:"summary"`
+	Distance float64    `json:"distance"`
+	Duration float64    `json:"duration"`
+	Steps    []osrmStep `json:"steps"`
+}
+
+type osrmStep struct {
+	Name     string       `json:"name"`
+	Mode     string       `json:"mode"`
+	Distance float64      `json:"distance"`
+	Duration float64      `json:"duration"`
+	Geometry string       `json:"geometry"`
+	Maneuver osrmManeuver `json:"maneuver"`
+}
+
+type osrmManeuver struct {
+	Type     string     `json:"type"`
+	Modifier string     `json:"modifier"`
+	Location [2]float64 `json:"location"`
+}
+
+// Directions will query the OSRM route service, or the trip service when the
+// waypoints are to be optimized
+func (o *OSRMProvider) Directions(ctx context.Context, request *Request) ([]katnavv1.Route, error) {
+	profile, ok := osrmProfiles[request.Mode]
+	if !ok {
+		return nil, &Error{Reason: ReasonInvalidRequest, Err: fmt.Errorf("osrm: mode %q is not supported", request.Mode)}
+	}
+
+	locations := append([]string{request.Origin}, request.Waypoints...)
+	locations = append(locations, request.Destination)
+	var coordinates []string
+	for x := range locations {
+		l, err := katnavv1.ParseLatLng(locations[x])
+		if err != nil {
+			return nil, &Error{Reason: ReasonInvalidRequest, Err: fmt.Errorf("osrm: %v", err)}
+		}
+		// OSRM expects the longitude first
+		coordinates = append(coordinates, fmt.Sprintf("%s,%s", formatFloat(l.Lng), formatFloat(l.Lat)))
+	}
+
+	q := url.Values{}
+	q.Set("steps", "true")
+	q.Set("overview", "full")
+	q.Set("geometries", "polyline")
+	var excludes []string
+	for x := range request.Avoid {
+		if exclude, ok := osrmExcludes[request.Avoid[x]]; ok {
+			excludes = append(excludes, exclude)
+		}
+	}
+	if len(excludes) != 0 {
+		q.Set("exclude", strings.Join(excludes, ","))
+	}
+
+	service := "route"
+	if request.OptimizeWaypoints && len(request.Waypoints) > 1 {
+		// The trip service solves the travelling salesman problem, but we
+		// still need to start and finish where we've been asked to
+		service = "trip"
+		q.Set("source", "first")
+		q.Set("destination", "last")
+		q.Set("roundtrip", "false")
+	} else if request.Alternatives {
+		q.Set("alternatives", "true")
+	}
+
+	u := fmt.Sprintf("%s/%s/v1/%s/%s?%s", o.baseURL, service, profile, strings.Join(coordinates, ";"), q.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return nil, &Error{Reason: ReasonUnavailable, Transient: true, Err: err}
+	}
+	defer resp.Body.Close()
+
+	var response osrmResponse
+	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		err = fmt.Errorf("osrm: unable to decode response (HTTP %d): %v", resp.StatusCode, err)
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return nil, &Error{Reason: ReasonOverQueryLimit, Transient: true, Err: err}
+		}
+		return nil, &Error{Reason: ReasonUnavailable, Transient: true, Err: err}
+	}
+	switch response.Code {
+	case "Ok":
+	case "NoRoute":
+		// Treated the same as Google returning no routes
+		return nil, nil
+	case "NoSegment", "NoTrips":
+		return nil, &Error{Reason: ReasonNotFound, Err: fmt.Errorf("osrm: %s - %s", response.Code, response.Message)}
+	case "TooBig":
+		return nil, &Error{Reason: ReasonMaxWaypointsExceeded, Err: fmt.Errorf("osrm: %s - %s", response.Code, response.Message)}
+	default:
+		return nil, &Error{Reason: ReasonInvalidRequest, Err: fmt.Errorf("osrm: %s - %s", response.Code, response.Message)}
+	}
+
+	waypoints := append([]osrmWaypoint{}, response.Waypoints...)
+	routes := response.Routes
+	var waypointOrder []int
+	if service == "trip" {
+		routes = response.Trips
+		// The waypoints are returned in the order they were requested, so
+		// sort them into the order that they will be visited in
+		sort.SliceStable(waypoints, func(i, j int) bool {
+			return waypoints[i].WaypointIndex < waypoints[j].WaypointIndex
+		})
+		for x := range response.Waypoints {
+			if x != 0 && x != len(response.Waypoints)-1 {
+				waypointOrder = append(waypointOrder, x-1)
+			}
+		}
+		sort.SliceStable(waypointOrder, func(i, j int) bool {
+			return response.Waypoints[waypointOrder[i]+1].WaypointIndex < response.Waypoints[waypointOrder[j]+1].WaypointIndex
+		})
+	}
+
+	var status []katnavv1.Route
+	for x := range routes {
+		route := osrmRouteStatus(routes[x], waypoints, newFormatter(request.Language, request.Units))
+		route.WaypointOrder = waypointOrder
+		status = append(status, route)
+	}
+	return status, nil
+}
+
+// osrmRouteStatus builds the status representation of a single route, the
+// waypoints need to be in the order that they are visited
+func osrmRouteStatus(route osrmRoute, waypoints []osrmWaypoint, format formatter) katnavv1.Route {
+	status := katnavv1.Route{
+		DistanceMeters:   int(route.Distance),
+		DurationSeconds:  int64(route.Duration),
+		OverviewPolyline: route.Geometry,
+	}
+	var summaries []string
+	for x := range route.Legs {
+		leg := katnavv1.Leg{
+			Distance:        format.humanDistance(int(route.Legs[x].Distance)),
+			DistanceMeters:  int(route.Legs[x].Distance),
+			Duration:        format.humanDuration(time.Duration(route.Legs[x].Duration) * time.Second),
+			DurationSeconds: int64(route.Legs[x].Duration),
+		}
+		if x+1 < len(waypoints) {
+			leg.StartLocation = waypoints[x].Name
+			leg.StartCoordinates = osrmLatLng(waypoints[x].Location)
+			leg.EndLocation = waypoints[x+1].Name
+			leg.EndCoordinates = osrmLatLng(waypoints[x+1].Location)
+		}
+		for y := range route.Legs[x].Steps {
+			leg.Steps = append(leg.Steps, osrmStepStatus(route.Legs[x].Steps[y]))
+		}
+		if route.Legs[x].Summary != "" {
+			summaries = append(summaries, route.Legs[x].Summary)
+		}
+		status.Legs = append(status.Legs, leg)
+	}
+	if len(status.Legs) != 0 {
+		status.StartLocation = status.Legs[0].StartLocation
+		status.EndLocation = status.Legs[len(status.Legs)-1].EndLocation
+	}
+	status.Summary = strings.Join(summaries, ", ")
+	status.Distance = format.humanDistance(status.DistanceMeters)
+	status.Duration = format.humanDuration(time.Duration(status.DurationSeconds) * time.Second)
+	return status
+}
+
+// osrmStepStatus builds the status representation of a single step
+func osrmStepStatus(step osrmStep) katnavv1.Step {
+	status := katnavv1.Step{
+		Instruction:     osrmInstruction(step),
+		Maneuver:        strings.ReplaceAll(strings.TrimSpace(step.Maneuver.Type+" "+step.Maneuver.Modifier), " ", "-"),
+		TravelMode:      step.Mode,
+		DistanceMeters:  int(step.Distance),
+		DurationSeconds: int64(step.Duration),
+		StartLocation:   osrmLatLng(step.Maneuver.Location),
+		EndLocation:     osrmLatLng(step.Maneuver.Location),
+		Polyline:        step.Geometry,
+	}
+	// The maneuver only tells us where the step begins, the end of the step
+	// is the last point of its geometry
+	if path, err := maps.DecodePolyline(step.Geometry); err == nil && len(path) != 0 {
+		status.EndLocation = googleLatLng(path[len(path)-1])
+	}
+	return status
+}
+
+// osrmInstruction creates a readable instruction as OSRM only returns the
+// maneuver and the name of the road
+func osrmInstruction(step osrmStep) string {
+	var instruction string
+	switch step.Maneuver.Type {
+	case "depart":
+		instruction = "Depart"
+	case "arrive":
+		return "Arrive at your destination"
+	case "":
+		instruction = "Continue"
+	default:
+		instruction = strings.ToUpper(step.Maneuver.Type[:1]) + step.Maneuver.Type[1:]
+		if step.Maneuver.Modifier != "" {
+			instruction += " " + step.Maneuver.Modifier
+		}
+	}
+	if step.Name != "" {
+		instruction += " onto " + step.Name
+	}
+	return instruction
+}
+
+// osrmLatLng converts OSRM coordinates, which are longitude first
+func osrmLatLng(l [2]float64) katnavv1.LatLng {
+	return katnavv1.LatLng{Lat: l[1], Lng: l[0]}
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+)
+
+const osrmRouteResponse = `{
+  "code": "Ok",
+  "routes": [{
+    "distance": 1500.4, "duration": 300.2, "geometry": "_p~iF~ps|U_ulLnnqC",
+    "legs": [{
+      "summary": "Main Street", "distance": 1500.4, "duration": 300.2,
+      "steps": [
+        {"name": "Main Street", "mode": "driving", "distance": 1500.4, "duration": 300.2, "geometry": "_p~iF~ps|U_ulLnnqC",
+         "maneuver": {"type": "depart", "location": [-120.2, 38.5]}},
+        {"name": "", "mode": "driving", "distance": 0, "duration": 0, "geometry": "",
+         "maneuver": {"type": "arrive", "location": [-120.95, 40.7]}}
+      ]
+    }]
+  }],
+  "waypoints": [
+    {"name": "Main Street", "location": [-120.2, 38.5]},
+    {"name": "High Street", "location": [-120.95, 40.7]}
+  ]
+}`
+
+const osrmTripResponse = `{
+  "code": "Ok",
+  "trips": [{"distance": 10, "duration": 10, "geometry": "", "legs": [
+    {"distance": 1, "duration": 1}, {"distance": 1, "duration": 1}, {"distance": 1, "duration": 1}
+  ]}],
+  "waypoints": [
+    {"name": "origin", "location": [0, 0], "waypoint_index": 0},
+    {"name": "first", "location": [1, 1], "waypoint_index": 2},
+    {"name": "second", "location": [2, 2], "waypoint_index": 1},
+    {"name": "destination", "location": [3, 3], "waypoint_index": 3}
+  ]
+}`
+
+func TestOSRMDirections(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		_, _ = w.Write([]byte(osrmRouteResponse))
+	}))
+	defer server.Close()
+
+	routes, err := NewOSRMProvider(server.URL).Directions(context.TODO(), &Request{
+		Origin:      "38.5,-120.2",
+		Destination: "40.7,-120.95",
+		Mode:        katnavv1.TravelModeDriving,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != "/route/v1/car/-120.2,38.5;-120.95,40.7" {
+		t.Errorf("unexpected request path %q", path)
+	}
+	if len(routes) != 1 || len(routes[0].Legs) != 1 || len(routes[0].Legs[0].Steps) != 2 {
+		t.Fatalf("unexpected routes %+v", routes)
+	}
+	route := routes[0]
+	if route.Summary != "Main Street" || route.StartLocation != "Main Street" || route.EndLocation != "High Street" {
+		t.Errorf("unexpected route %+v", route)
+	}
+	if route.DistanceMeters != 1500 || route.DurationSeconds != 300 {
+		t.Errorf("unexpected totals %d m, %d s", route.DistanceMeters, route.DurationSeconds)
+	}
+	if route.OverviewPolyline != "_p~iF~ps|U_ulLnnqC" {
+		t.Errorf("unexpected overview polyline %q", route.OverviewPolyline)
+	}
+	step := route.Legs[0].Steps[0]
+	if step.Instruction != "Depart onto Main Street" || step.Maneuver != "depart" {
+		t.Errorf("unexpected step %+v", step)
+	}
+	if step.EndLocation != (katnavv1.LatLng{Lat: 40.7, Lng: -120.95}) {
+		t.Errorf("unexpected step end %+v", step.EndLocation)
+	}
+}
+
+func TestOSRMOptimizedWaypoints(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/trip/v1/foot/0,0;1,1;2,2;3,3" {
+			t.Errorf("unexpected request path %q", r.URL.Path)
+		}
+		_, _ = w.Write([]byte(osrmTripResponse))
+	}))
+	defer server.Close()
+
+	routes, err := NewOSRMProvider(server.URL).Directions(context.TODO(), &Request{
+		Origin:            "0,0",
+		Waypoints:         []string{"1,1", "2,2"},
+		OptimizeWaypoints: true,
+		Destination:       "3,3",
+		Mode:              katnavv1.TravelModeWalking,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(routes[0].WaypointOrder, []int{1, 0}) {
+		t.Errorf("unexpected waypoint order %v", routes[0].WaypointOrder)
+	}
+	if routes[0].Legs[0].EndLocation != "second" || routes[0].Legs[1].EndLocation != "first" {
+		t.Errorf("legs are not in the visited order %+v", routes[0].Legs)
+	}
+}
+
+func TestOSRMRejectsAddresses(t *testing.T) {
+	_, err := NewOSRMProvider("http://localhost").Directions(context.TODO(), &Request{
+		Origin:      "Kings Cross, London",
+		Destination: "51.5,-0.1",
+		Mode:        katnavv1.TravelModeDriving,
+	})
+	if err == nil {
+		t.Error("expected an error for an address")
+	}
+}
@@ -0,0 +1,39 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+)
+
+// PlacesProvider is a backend that is able to search for places
+type PlacesProvider interface {
+	// NearbySearch returns the places that match the request around a location
+	NearbySearch(ctx context.Context, request *PlacesRequest) ([]Place, error)
+}
+
+// PlacesRequest is a search for places of a type, or matching a keyword,
+// within a radius of a location
+type PlacesRequest struct {
+	Location     katnavv1.LatLng
+	RadiusMeters int
+	Type         string
+	Keyword      string
+	Language     string
+}
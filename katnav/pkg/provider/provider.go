@@ -0,0 +1,82 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"time"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+)
+
+const (
+	// Google uses the Google Maps APIs
+	Google = "google"
+	// OSRM uses a (self-hosted) Open Source Routing Machine
+	OSRM = "osrm"
+)
+
+// RoutingProvider is a backend that is able to calculate routes for a journey
+type RoutingProvider interface {
+	// Directions returns every route for the request, the first route is the
+	// one that the provider recommends
+	Directions(ctx context.Context, request *Request) ([]katnavv1.Route, error)
+}
+
+// Request is everything a provider needs to know in order to find a route
+type Request struct {
+	Origin            string
+	Destination       string
+	Waypoints         []string
+	OptimizeWaypoints bool
+	Mode              katnavv1.TravelMode
+	Avoid             []katnavv1.Avoid
+	Alternatives      bool
+	// DepartureTime is "now" or an RFC 3339 time
+	DepartureTime string
+	ArrivalTime   *time.Time
+	TrafficModel  katnavv1.TrafficModel
+	Language      string
+	Region        string
+	Units         katnavv1.Units
+}
+
+// NewRequest builds a request from the spec of a Directions object, any mode
+// that hasn't been set will default to driving
+func NewRequest(spec *katnavv1.DirectionsSpec) *Request {
+	request := &Request{
+		Origin:            spec.Source,
+		Destination:       spec.Destination,
+		Waypoints:         spec.Waypoints,
+		OptimizeWaypoints: spec.OptimizeWaypoints,
+		Mode:              spec.Mode,
+		Avoid:             spec.Avoid,
+		Alternatives:      spec.Alternatives,
+		DepartureTime:     spec.DepartureTime,
+		TrafficModel:      spec.TrafficModel,
+		Language:          spec.Language,
+		Region:            spec.Region,
+		Units:             spec.Units,
+	}
+	if spec.ArrivalTime != nil {
+		request.ArrivalTime = &spec.ArrivalTime.Time
+	}
+	if request.Mode == "" {
+		request.Mode = katnavv1.TravelModeDriving
+	}
+	return request
+}
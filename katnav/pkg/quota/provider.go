@@ -0,0 +1,171 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/provider"
+)
+
+// limitedProvider acquires from the limiter before every request
+type limitedProvider struct {
+	provider.RoutingProvider
+	limiter *Limiter
+}
+
+// Wrap returns a provider where every request counts towards the limiter
+func Wrap(routingProvider provider.RoutingProvider, limiter *Limiter) provider.RoutingProvider {
+	return &limitedProvider{RoutingProvider: routingProvider, limiter: limiter}
+}
+
+func (p *limitedProvider) Directions(ctx context.Context, request *provider.Request) ([]katnavv1.Route, error) {
+	if err := p.limiter.Acquire(ctx); err != nil {
+		return nil, limitError(err)
+	}
+	return p.RoutingProvider.Directions(ctx, request)
+}
+
+// limitedGeocoder acquires from the limiter before every request
+type limitedGeocoder struct {
+	provider.Geocoder
+	limiter *Limiter
+}
+
+// WrapGeocoder returns a geocoder where every request counts towards the limiter
+func WrapGeocoder(geocoder provider.Geocoder, limiter *Limiter) provider.Geocoder {
+	return &limitedGeocoder{Geocoder: geocoder, limiter: limiter}
+}
+
+func (g *limitedGeocoder) Geocode(ctx context.Context, address string) (provider.Place, error) {
+	if err := g.limiter.Acquire(ctx); err != nil {
+		return provider.Place{}, limitError(err)
+	}
+	return g.Geocoder.Geocode(ctx, address)
+}
+
+func (g *limitedGeocoder) ReverseGeocode(ctx context.Context, coordinates katnavv1.LatLng) (provider.Place, error) {
+	if err := g.limiter.Acquire(ctx); err != nil {
+		return provider.Place{}, limitError(err)
+	}
+	return g.Geocoder.ReverseGeocode(ctx, coordinates)
+}
+
+func (g *limitedGeocoder) TimeZone(ctx context.Context, coordinates katnavv1.LatLng, at time.Time) (string, error) {
+	if err := g.limiter.Acquire(ctx); err != nil {
+		return "", limitError(err)
+	}
+	return g.Geocoder.TimeZone(ctx, coordinates, at)
+}
+
+// limitedMatrix acquires from the limiter before every request
+type limitedMatrix struct {
+	provider.MatrixProvider
+	limiter *Limiter
+}
+
+// WrapMatrix returns a matrix provider where every request counts towards the
+// limiter, the origins are split into chunks that fit into a single request
+// (see provider.MatrixOriginsPerRequest) and each chunk is counted as a request
+func WrapMatrix(matrixProvider provider.MatrixProvider, limiter *Limiter) provider.MatrixProvider {
+	return &limitedMatrix{MatrixProvider: matrixProvider, limiter: limiter}
+}
+
+func (m *limitedMatrix) DistanceMatrix(ctx context.Context, request *provider.MatrixRequest) ([]katnavv1.MatrixRow, error) {
+	perRequest := provider.MatrixOriginsPerRequest(len(request.Destinations))
+	rows := make([]katnavv1.MatrixRow, 0, len(request.Origins))
+	for start := 0; start < len(request.Origins); start += perRequest {
+		end := start + perRequest
+		if end > len(request.Origins) {
+			end = len(request.Origins)
+		}
+		if err := m.limiter.Acquire(ctx); err != nil {
+			return nil, limitError(err)
+		}
+		chunk := *request
+		chunk.Origins = request.Origins[start:end]
+		results, err := m.MatrixProvider.DistanceMatrix(ctx, &chunk)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, results...)
+	}
+	return rows, nil
+}
+
+// limitedElevation acquires from the limiter before every request
+type limitedElevation struct {
+	provider.ElevationProvider
+	limiter *Limiter
+}
+
+// WrapElevation returns an elevation provider where every request counts
+// towards the limiter, the locations are split into chunks of
+// provider.MaxElevationLocations and each chunk is counted as a request
+func WrapElevation(elevationProvider provider.ElevationProvider, limiter *Limiter) provider.ElevationProvider {
+	return &limitedElevation{ElevationProvider: elevationProvider, limiter: limiter}
+}
+
+func (e *limitedElevation) Elevation(ctx context.Context, locations []katnavv1.LatLng) ([]float64, error) {
+	elevations := make([]float64, 0, len(locations))
+	for start := 0; start < len(locations); start += provider.MaxElevationLocations {
+		end := start + provider.MaxElevationLocations
+		if end > len(locations) {
+			end = len(locations)
+		}
+		if err := e.limiter.Acquire(ctx); err != nil {
+			return nil, limitError(err)
+		}
+		results, err := e.ElevationProvider.Elevation(ctx, locations[start:end])
+		if err != nil {
+			return nil, err
+		}
+		elevations = append(elevations, results...)
+	}
+	return elevations, nil
+}
+
+// limitedPlaces acquires from the limiter before every request
+type limitedPlaces struct {
+	provider.PlacesProvider
+	limiter *Limiter
+}
+
+// WrapPlaces returns a places provider where every request counts towards the
+// limiter
+func WrapPlaces(placesProvider provider.PlacesProvider, limiter *Limiter) provider.PlacesProvider {
+	return &limitedPlaces{PlacesProvider: placesProvider, limiter: limiter}
+}
+
+func (p *limitedPlaces) NearbySearch(ctx context.Context, request *provider.PlacesRequest) ([]provider.Place, error) {
+	if err := p.limiter.Acquire(ctx); err != nil {
+		return nil, limitError(err)
+	}
+	return p.PlacesProvider.NearbySearch(ctx, request)
+}
+
+// limitError converts an error from the limiter into a provider error
+func limitError(err error) error {
+	var exhausted *ExhaustedError
+	if errors.As(err, &exhausted) {
+		return &provider.Error{Reason: provider.ReasonQuotaExhausted, Err: err}
+	}
+	return &provider.Error{Reason: provider.ReasonUnavailable, Transient: true, Err: err}
+}
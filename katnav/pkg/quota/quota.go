@@ -0,0 +1,145 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	requestsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "katnav_provider_requests_total",
+		Help: "Number of requests that have been made to routing providers",
+	})
+	remainingBudget = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "katnav_provider_budget_remaining",
+		Help: "Number of provider requests remaining in the daily budget",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(requestsTotal, remainingBudget)
+}
+
+// ExhaustedError is returned once the daily budget has been used up
+type ExhaustedError struct {
+	ResetTime time.Time
+}
+
+func (e *ExhaustedError) Error() string {
+	return fmt.Sprintf("daily provider budget exhausted, it will reset at %s", e.ResetTime.Format(time.RFC3339))
+}
+
+// Limiter is shared by every worker so that all provider requests are limited
+// to a rate and a daily budget, the budget resets at midnight in location
+type Limiter struct {
+	limiter  *rate.Limiter
+	budget   int
+	location *time.Location
+
+	mu        sync.Mutex
+	used      int
+	resetTime time.Time
+}
+
+// NewLimiter creates a limiter, a qps of zero doesn't limit the rate and a
+// budget of zero doesn't limit the number of requests each day
+func NewLimiter(qps float64, budget int, location *time.Location) *Limiter {
+	limit := rate.Inf
+	if qps > 0 {
+		limit = rate.Limit(qps)
+	}
+	l := &Limiter{
+		limiter:  rate.NewLimiter(limit, 1),
+		budget:   budget,
+		location: location,
+	}
+	l.resetTime = l.nextReset(time.Now())
+	remainingBudget.Set(float64(l.Remaining()))
+	return l
+}
+
+// nextReset returns the next midnight after now
+func (l *Limiter) nextReset(now time.Time) time.Time {
+	now = now.In(l.location)
+	return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, l.location)
+}
+
+// Acquire takes a request from the daily budget and then waits until the rate
+// allows the request to be made
+func (l *Limiter) Acquire(ctx context.Context) error {
+	l.mu.Lock()
+	if now := time.Now(); !now.Before(l.resetTime) {
+		l.used = 0
+		l.resetTime = l.nextReset(now)
+	}
+	if l.budget > 0 && l.used >= l.budget {
+		resetTime := l.resetTime
+		l.mu.Unlock()
+		return &ExhaustedError{ResetTime: resetTime}
+	}
+	l.used++
+	remainingBudget.Set(float64(l.remaining()))
+	l.mu.Unlock()
+
+	requestsTotal.Inc()
+	return l.limiter.Wait(ctx)
+}
+
+// Restore sets how much of the budget has been used, so that a restart of the
+// controller doesn't reset the budget. It is ignored once resetTime has passed.
+func (l *Limiter) Restore(used int, resetTime time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !resetTime.Equal(l.resetTime) || used <= l.used {
+		return
+	}
+	l.used = used
+	remainingBudget.Set(float64(l.remaining()))
+}
+
+// Usage returns the state of the limiter
+func (l *Limiter) Usage() (qps float64, budget, used int, resetTime time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.limiter.Limit() != rate.Inf {
+		qps = float64(l.limiter.Limit())
+	}
+	return qps, l.budget, l.used, l.resetTime
+}
+
+// Remaining returns the number of requests left today, this is -1 when there
+// is no budget
+func (l *Limiter) Remaining() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.remaining()
+}
+
+func (l *Limiter) remaining() int {
+	if l.budget <= 0 {
+		return -1
+	}
+	return l.budget - l.used
+}
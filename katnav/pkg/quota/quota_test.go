@@ -0,0 +1,120 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/provider"
+)
+
+func TestLimiterBudget(t *testing.T) {
+	l := NewLimiter(0, 2, time.UTC)
+	for x := 0; x < 2; x++ {
+		if err := l.Acquire(context.TODO()); err != nil {
+			t.Fatalf("request %d: %v", x, err)
+		}
+	}
+	var exhausted *ExhaustedError
+	if err := l.Acquire(context.TODO()); !errors.As(err, &exhausted) {
+		t.Fatalf("expected the budget to be exhausted, got %v", err)
+	}
+	if _, _, _, resetTime := l.Usage(); !exhausted.ResetTime.Equal(resetTime) || !resetTime.After(time.Now()) {
+		t.Errorf("unexpected reset time %s", exhausted.ResetTime)
+	}
+	if l.Remaining() != 0 {
+		t.Errorf("expected no budget remaining, got %d", l.Remaining())
+	}
+}
+
+func TestLimiterRestore(t *testing.T) {
+	l := NewLimiter(0, 10, time.UTC)
+	_, _, _, resetTime := l.Usage()
+
+	l.Restore(4, resetTime.Add(-24*time.Hour))
+	if l.Remaining() != 10 {
+		t.Errorf("usage from a previous day was restored")
+	}
+	l.Restore(4, resetTime)
+	if l.Remaining() != 6 {
+		t.Errorf("expected 6 requests remaining, got %d", l.Remaining())
+	}
+}
+
+type fakeElevation struct {
+	requests []int
+}
+
+func (f *fakeElevation) Elevation(ctx context.Context, locations []katnavv1.LatLng) ([]float64, error) {
+	f.requests = append(f.requests, len(locations))
+	return make([]float64, len(locations)), nil
+}
+
+func TestWrapElevation(t *testing.T) {
+	l := NewLimiter(0, 10, time.UTC)
+	fake := &fakeElevation{}
+	locations := make([]katnavv1.LatLng, provider.MaxElevationLocations+88)
+	elevations, err := WrapElevation(fake, l).Elevation(context.TODO(), locations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(elevations) != len(locations) {
+		t.Errorf("expected %d elevations, got %d", len(locations), len(elevations))
+	}
+	if len(fake.requests) != 2 || fake.requests[0] != provider.MaxElevationLocations || fake.requests[1] != 88 {
+		t.Errorf("unexpected requests %v", fake.requests)
+	}
+	if l.Remaining() != 8 {
+		t.Errorf("expected a request per chunk, got %d remaining", l.Remaining())
+	}
+}
+
+type fakeMatrix struct {
+	requests []int
+}
+
+func (f *fakeMatrix) DistanceMatrix(ctx context.Context, request *provider.MatrixRequest) ([]katnavv1.MatrixRow, error) {
+	f.requests = append(f.requests, len(request.Origins))
+	return make([]katnavv1.MatrixRow, len(request.Origins)), nil
+}
+
+func TestWrapMatrix(t *testing.T) {
+	l := NewLimiter(0, 10, time.UTC)
+	fake := &fakeMatrix{}
+	request := &provider.MatrixRequest{
+		Origins:      make([]string, 25),
+		Destinations: make([]string, 25),
+	}
+	rows, err := WrapMatrix(fake, l).DistanceMatrix(context.TODO(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 25 {
+		t.Errorf("expected 25 rows, got %d", len(rows))
+	}
+	// 4 origins of 25 destinations fit into each request
+	if len(fake.requests) != 7 || fake.requests[0] != 4 || fake.requests[6] != 1 {
+		t.Errorf("unexpected requests %v", fake.requests)
+	}
+	if l.Remaining() != 3 {
+		t.Errorf("expected a request per chunk, got %d remaining", l.Remaining())
+	}
+}
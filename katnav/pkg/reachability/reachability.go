@@ -0,0 +1,82 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reachability
+
+import (
+	"sort"
+	"time"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/provider"
+)
+
+// Rank returns a candidate for each of the names, elements are the journeys
+// to them in the same order. The candidates are ordered by travel time, using
+// the time in traffic when it is known, and the candidates without a journey
+// are left at the end in the order they were given.
+func Rank(names []string, elements []katnavv1.MatrixElement, budget time.Duration, language string) []katnavv1.RankedCandidate {
+	candidates := make([]katnavv1.RankedCandidate, len(names))
+	for x, name := range names {
+		candidates[x].Name = name
+		if x >= len(elements) {
+			candidates[x].ErrorCode = provider.ReasonZeroResults
+			continue
+		}
+		element := elements[x]
+		candidates[x].Destination = element.Destination
+		candidates[x].ErrorCode = element.ErrorCode
+		if element.ErrorCode != "" {
+			continue
+		}
+		expected := time.Duration(element.DurationSeconds) * time.Second
+		candidates[x].Duration = element.Duration
+		if element.DurationInTrafficSeconds != 0 {
+			expected = time.Duration(element.DurationInTrafficSeconds) * time.Second
+			candidates[x].Duration = provider.FormatDuration(expected, language)
+		}
+		candidates[x].Distance = element.Distance
+		candidates[x].DistanceMeters = element.DistanceMeters
+		candidates[x].DurationSeconds = int64(expected.Seconds())
+		candidates[x].MarginSeconds = int64((budget - expected).Seconds())
+		candidates[x].Reachable = expected <= budget
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if (candidates[i].ErrorCode == "") != (candidates[j].ErrorCode == "") {
+			return candidates[i].ErrorCode == ""
+		}
+		return candidates[i].ErrorCode == "" && candidates[i].DurationSeconds < candidates[j].DurationSeconds
+	})
+	for x := range candidates {
+		if candidates[x].ErrorCode == "" {
+			candidates[x].Rank = x + 1
+		}
+	}
+	return candidates
+}
+
+// Reachable returns the number of candidates that can be reached within the
+// budget
+func Reachable(candidates []katnavv1.RankedCandidate) int {
+	var reachable int
+	for x := range candidates {
+		if candidates[x].Reachable {
+			reachable++
+		}
+	}
+	return reachable
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reachability
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/provider"
+)
+
+func TestRank(t *testing.T) {
+	elements := []katnavv1.MatrixElement{
+		{Destination: "Depot A", Distance: "20 km", DistanceMeters: 20000, Duration: "25 min", DurationSeconds: 1500, DurationInTrafficSeconds: 2400},
+		{Destination: "Depot B", ErrorCode: provider.ReasonZeroResults},
+		{Destination: "Depot C", Distance: "5 km", DistanceMeters: 5000, Duration: "10 min", DurationSeconds: 600},
+		{Destination: "Depot D", Distance: "15 km", DistanceMeters: 15000, Duration: "30 min", DurationSeconds: 1800},
+	}
+	candidates := Rank([]string{"a", "b", "c", "d", "e"}, elements, 30*time.Minute, "")
+
+	var names []string
+	for _, candidate := range candidates {
+		names = append(names, candidate.Name)
+	}
+	if want := []string{"c", "d", "a", "b", "e"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("expected the order %v, got %v", want, names)
+	}
+	if c := candidates[0]; c.Rank != 1 || !c.Reachable || c.MarginSeconds != 1200 || c.Duration != "10 min" {
+		t.Errorf("unexpected nearest candidate %+v", c)
+	}
+	if d := candidates[1]; d.Rank != 2 || !d.Reachable || d.MarginSeconds != 0 {
+		t.Errorf("expected a journey that takes the whole budget to be reachable, got %+v", d)
+	}
+	if a := candidates[2]; a.Rank != 3 || a.Reachable || a.MarginSeconds != -600 || a.Duration != "40 min" || a.DurationSeconds != 2400 {
+		t.Errorf("expected the time in traffic to be over the budget, got %+v", a)
+	}
+	for _, missing := range candidates[3:] {
+		if missing.Rank != 0 || missing.Reachable || missing.ErrorCode != provider.ReasonZeroResults {
+			t.Errorf("expected no journey to %s, got %+v", missing.Name, missing)
+		}
+	}
+	if reachable := Reachable(candidates); reachable != 2 {
+		t.Errorf("expected 2 reachable candidates, got %d", reachable)
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routecache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/provider"
+)
+
+// Entry is a set of routes that were returned by a provider
+type Entry struct {
+	Routes  []katnavv1.Route `json:"routes"`
+	Created time.Time        `json:"created"`
+}
+
+// Store persists the cache so that it survives a restart of the controller
+type Store interface {
+	Load(ctx context.Context) (map[string]Entry, error)
+	Save(ctx context.Context, entries map[string]Entry) error
+}
+
+// Cache holds the routes for requests so that objects asking for the same
+// journey share a single query, it is safe to use from multiple workers
+type Cache struct {
+	ttl   time.Duration
+	store Store
+
+	mu      sync.Mutex
+	entries map[string]Entry
+}
+
+// New creates a cache where entries expire after ttl, store can be nil to
+// only keep the cache in memory
+func New(ttl time.Duration, store Store) *Cache {
+	return &Cache{ttl: ttl, store: store}
+}
+
+// Key returns the cache key for a request to the named provider, requests that
+// only differ by case, whitespace or the order of avoidances share a key
+func Key(providerName string, request *provider.Request) string {
+	normalised := *request
+	normalised.Origin = normalise(request.Origin)
+	normalised.Destination = normalise(request.Destination)
+	normalised.Waypoints = nil
+	for x := range request.Waypoints {
+		normalised.Waypoints = append(normalised.Waypoints, normalise(request.Waypoints[x]))
+	}
+	normalised.Avoid = append([]katnavv1.Avoid{}, request.Avoid...)
+	sort.Slice(normalised.Avoid, func(i, j int) bool { return normalised.Avoid[i] < normalised.Avoid[j] })
+
+	b, _ := json.Marshal(normalised)
+	sum := sha256.Sum256(append([]byte(providerName+"/"), b...))
+	return hex.EncodeToString(sum[:16])
+}
+
+func normalise(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// Get returns the routes for a key if they were cached within the TTL, a
+// maxAge of zero means that only the TTL applies
+func (c *Cache) Get(ctx context.Context, key string, maxAge time.Duration) (Entry, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.load(ctx); err != nil {
+		return Entry{}, false, err
+	}
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return Entry{}, false, nil
+	}
+	age := time.Since(entry.Created)
+	if age > c.ttl || (maxAge > 0 && age > maxAge) {
+		return Entry{}, false, nil
+	}
+	return entry, true, nil
+}
+
+// Put adds routes to the cache, expired entries are removed before the cache
+// is persisted
+func (c *Cache) Put(ctx context.Context, key string, routes []katnavv1.Route) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.load(ctx); err != nil {
+		return err
+	}
+
+	c.entries[key] = Entry{Routes: routes, Created: time.Now()}
+	for k := range c.entries {
+		if time.Since(c.entries[k].Created) > c.ttl {
+			delete(c.entries, k)
+		}
+	}
+	if c.store == nil {
+		return nil
+	}
+	return c.store.Save(ctx, c.entries)
+}
+
+// load reads the persisted cache the first time it is needed, as the store may
+// not be usable until the manager has started
+func (c *Cache) load(ctx context.Context) error {
+	if c.entries != nil {
+		return nil
+	}
+	c.entries = map[string]Entry{}
+	if c.store == nil {
+		return nil
+	}
+	entries, err := c.store.Load(ctx)
+	if err != nil {
+		c.entries = nil
+		return err
+	}
+	if entries != nil {
+		c.entries = entries
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routecache
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	katnavv1 "github.com/thebsdbox/kubernetes-controllers/katnav/api/v1"
+	"github.com/thebsdbox/kubernetes-controllers/katnav/pkg/provider"
+)
+
+func TestKeyIsNormalised(t *testing.T) {
+	a := Key(provider.Google, &provider.Request{
+		Origin:      "Kings Cross,  London",
+		Destination: "Greenwich",
+		Avoid:       []katnavv1.Avoid{katnavv1.AvoidTolls, katnavv1.AvoidFerries},
+	})
+	b := Key(provider.Google, &provider.Request{
+		Origin:      "kings cross, london ",
+		Destination: "GREENWICH",
+		Avoid:       []katnavv1.Avoid{katnavv1.AvoidFerries, katnavv1.AvoidTolls},
+	})
+	if a != b {
+		t.Error("equivalent requests have different keys")
+	}
+	if a == Key(provider.OSRM, &provider.Request{Origin: "kings cross, london", Destination: "greenwich"}) {
+		t.Error("different providers share a key")
+	}
+}
+
+func TestFileStorePersistsCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "routecache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store := &FileStore{Path: filepath.Join(dir, "cache.json")}
+
+	routes := []katnavv1.Route{{Summary: "A2"}}
+	if err = New(time.Hour, store).Put(context.TODO(), "key", routes); err != nil {
+		t.Fatal(err)
+	}
+
+	// A new cache is the same as the controller restarting
+	cache := New(time.Hour, store)
+	entry, found, err := cache.Get(context.TODO(), "key", 0)
+	if err != nil || !found || entry.Routes[0].Summary != "A2" {
+		t.Fatalf("cached route wasn't loaded, found %t, err %v", found, err)
+	}
+	if _, found, _ = cache.Get(context.TODO(), "key", time.Nanosecond); found {
+		t.Error("entry older than maxAge was returned")
+	}
+	if _, found, _ = New(time.Nanosecond, store).Get(context.TODO(), "key", 0); found {
+		t.Error("expired entry was returned")
+	}
+}
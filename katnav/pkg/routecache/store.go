@@ -0,0 +1,160 @@
+/*
+Copyright 2021 Dan Finneran.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routecache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// FileStore persists the cache as JSON in a local file, which should be on a
+// volume that outlives the pod
+type FileStore struct {
+	Path string
+}
+
+// Load reads the cache, a missing file is an empty cache
+func (f *FileStore) Load(ctx context.Context) (map[string]Entry, error) {
+	b, err := ioutil.ReadFile(f.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries map[string]Entry
+	return entries, json.Unmarshal(b, &entries)
+}
+
+// Save writes the cache to a temporary file that is then renamed, so a crash
+// will never leave a partially written cache
+func (f *FileStore) Save(ctx context.Context, entries map[string]Entry) error {
+	b, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(f.Path), filepath.Base(f.Path))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.Path)
+}
+
+// configMapKey is where the compressed cache is kept within the ConfigMap
+const configMapKey = "routes.json.gz"
+
+// maxConfigMapSize leaves some headroom below the 1MiB limit of a ConfigMap
+const maxConfigMapSize = 900 * 1024
+
+// ConfigMapStore persists the cache as compressed JSON in a ConfigMap, the
+// Reader should not be a cached client as the cache is only read once
+type ConfigMapStore struct {
+	Reader client.Reader
+	Writer client.Writer
+	types.NamespacedName
+}
+
+// Load reads the cache, a missing ConfigMap is an empty cache
+func (c *ConfigMapStore) Load(ctx context.Context) (map[string]Entry, error) {
+	var configMap corev1.ConfigMap
+	err := c.Reader.Get(ctx, c.NamespacedName, &configMap)
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, ok := configMap.BinaryData[configMapKey]
+	if !ok {
+		return nil, nil
+	}
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	var entries map[string]Entry
+	return entries, json.NewDecoder(r).Decode(&entries)
+}
+
+// Save writes the cache, the oldest entries are left out if the cache will
+// not fit within a ConfigMap
+func (c *ConfigMapStore) Save(ctx context.Context, entries map[string]Entry) error {
+	keys := make([]string, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return entries[keys[i]].Created.After(entries[keys[j]].Created) })
+
+	var data []byte
+	for len(keys) != 0 {
+		persisted := map[string]Entry{}
+		for _, k := range keys {
+			persisted[k] = entries[k]
+		}
+		var err error
+		data, err = compress(persisted)
+		if err != nil {
+			return err
+		}
+		if len(data) <= maxConfigMapSize {
+			break
+		}
+		keys = keys[:len(keys)/2]
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: c.Namespace, Name: c.Name},
+		BinaryData: map[string][]byte{configMapKey: data},
+	}
+	err := c.Writer.Update(ctx, configMap)
+	if errors.IsNotFound(err) {
+		return c.Writer.Create(ctx, configMap)
+	}
+	return err
+}
+
+func compress(entries map[string]Entry) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(w).Encode(entries); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}